@@ -0,0 +1,78 @@
+package code
+
+import (
+	"errors"
+	"strings"
+
+	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+// awsErrorCategories maps the error codes of the AWS APIs to the categories.
+var awsErrorCategories = map[string]Category{
+	"EntityNotFoundException":              NotFound,
+	"ResourceNotFoundException":            NotFound,
+	"NoSuchEntity":                         NotFound,
+	"AccessDeniedException":                Permission,
+	"InvalidGrantException":                Permission,
+	"UnauthorizedException":                Permission,
+	"UnrecognizedClientException":          Unauthenticated,
+	"InvalidClientTokenId":                 Unauthenticated,
+	"ExpiredTokenException":                Unauthenticated,
+	"ExpiredToken":                         Unauthenticated,
+	"AlreadyExistsException":               AlreadyExists,
+	"ThrottlingException":                  Throttled,
+	"Throttling":                           Throttled,
+	"TooManyRequestsException":             Throttled,
+	"RequestLimitExceeded":                 Throttled,
+	"ConcurrentModificationException":      Conflict,
+	"VersionMismatchException":             Conflict,
+	"ConflictException":                    Conflict,
+	"InvalidInputException":                InvalidInput,
+	"ValidationException":                  InvalidInput,
+	"ResourceNumberLimitExceededException": InvalidInput,
+	"InternalServiceException":             Transient,
+	"InternalFailure":                      Transient,
+	"OperationTimeoutException":            Transient,
+	"ServiceUnavailable":                   Transient,
+	"ServiceUnavailableException":          Transient,
+}
+
+// FromAWS maps the error of the AWS SDK into Error. It returns nil for nil.
+func FromAWS(service, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{
+		Service:   service,
+		Operation: operation,
+		Category:  Fatal,
+		Message:   err.Error(),
+		Err:       err,
+	}
+	var re *awsHttp.ResponseError
+	if errors.As(err, &re) {
+		e.Status = re.HTTPStatusCode()
+		e.Category = CategoryOfHTTPStatus(e.Status)
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		e.Code = apiErr.ErrorCode()
+		e.Message = apiErr.ErrorMessage()
+		if category := CategoryOfAWSCode(e.Code); category != Fatal {
+			e.Category = category
+		}
+	}
+	return e
+}
+
+// CategoryOfAWSCode returns the category of the error code of the AWS APIs. Unknown codes are Fatal.
+func CategoryOfAWSCode(errorCode string) Category {
+	if category, ok := awsErrorCategories[errorCode]; ok {
+		return category
+	}
+	if strings.HasSuffix(errorCode, "NotFoundException") {
+		return NotFound
+	}
+	return Fatal
+}
@@ -0,0 +1,129 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Category classifies the errors of the repositories, so that the connectors decide to skip the asset, retry the call or abort the run.
+// Permission is the missing permission on the asset, and Unauthenticated is the invalid or expired credential that fails every call.
+// AlreadyExists is the resource that the create path doesn't need to create again.
+type Category string
+
+const (
+	NotFound        Category = "NOT_FOUND"
+	Permission      Category = "PERMISSION"
+	Unauthenticated Category = "UNAUTHENTICATED"
+	AlreadyExists   Category = "ALREADY_EXISTS"
+	Throttled       Category = "THROTTLED"
+	Conflict        Category = "CONFLICT"
+	InvalidInput    Category = "INVALID_INPUT"
+	Transient       Category = "TRANSIENT"
+	Fatal           Category = "FATAL"
+)
+
+// Decision is what the connector does with the error.
+type Decision string
+
+const (
+	Skip  Decision = "SKIP"
+	Retry Decision = "RETRY"
+	Abort Decision = "ABORT"
+)
+
+// Error is the error that every repository returns for the failures of the services.
+// Code is the error code of the service, such as EntityNotFoundException, and Status is the HTTP status code if any.
+type Error struct {
+	Service   string
+	Operation string
+	Category  Category
+	Code      string
+	Status    int
+	Message   string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Failed to %s.%s. %s(%s %d): %s", e.Service, e.Operation, e.Category, e.Code, e.Status, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CategoryOf returns the category of the error. The errors that aren't mapped by the repositories are Fatal.
+func CategoryOf(err error) Category {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Category
+	}
+	return Fatal
+}
+
+// Is returns true if the category of the error is the given one.
+func Is(err error, category Category) bool {
+	return err != nil && CategoryOf(err) == category
+}
+
+// Decide returns whether the asset is skipped, the call is retried or the run is aborted for the error.
+func Decide(err error) Decision {
+	switch CategoryOf(err) {
+	case NotFound, Permission, AlreadyExists, InvalidInput:
+		return Skip
+	case Throttled, Conflict, Transient:
+		return Retry
+	default:
+		return Abort
+	}
+}
+
+// CategoryOfHTTPStatus returns the category of the HTTP status code.
+func CategoryOfHTTPStatus(status int) Category {
+	switch {
+	case status == http.StatusNotFound:
+		return NotFound
+	case status == http.StatusUnauthorized:
+		return Unauthenticated
+	case status == http.StatusForbidden:
+		return Permission
+	case status == http.StatusTooManyRequests:
+		return Throttled
+	case status == http.StatusConflict, status == http.StatusPreconditionFailed:
+		return Conflict
+	case status == http.StatusRequestTimeout, status >= http.StatusInternalServerError:
+		return Transient
+	case status >= http.StatusBadRequest:
+		return InvalidInput
+	default:
+		return Fatal
+	}
+}
+
+// FromHTTPStatus returns the error for the response of the HTTP API.
+func FromHTTPStatus(service, operation string, status int, message string) error {
+	return &Error{
+		Service:   service,
+		Operation: operation,
+		Category:  CategoryOfHTTPStatus(status),
+		Code:      http.StatusText(status),
+		Status:    status,
+		Message:   message,
+	}
+}
+
+// DoWithRetry calls fn until it succeeds, fails with the error that isn't retried, or is called maxAttempts times.
+// The wait before the retry starts from backoff and doubles on every retry.
+func DoWithRetry(maxAttempts int, backoff time.Duration, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || Decide(err) != Retry || attempt >= maxAttempts {
+			return err
+		}
+		time.Sleep(backoff * time.Duration(1<<(attempt-1)))
+	}
+}
@@ -0,0 +1,148 @@
+package code_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"quollio-reverse-agent/common/code"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDecide(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want code.Decision
+	}{
+		{name: "not found", err: &code.Error{Category: code.NotFound}, want: code.Skip},
+		{name: "permission", err: &code.Error{Category: code.Permission}, want: code.Skip},
+		{name: "unauthenticated", err: &code.Error{Category: code.Unauthenticated}, want: code.Abort},
+		{name: "already exists", err: &code.Error{Category: code.AlreadyExists}, want: code.Skip},
+		{name: "invalid input", err: &code.Error{Category: code.InvalidInput}, want: code.Skip},
+		{name: "throttled", err: &code.Error{Category: code.Throttled}, want: code.Retry},
+		{name: "conflict", err: &code.Error{Category: code.Conflict}, want: code.Retry},
+		{name: "transient", err: &code.Error{Category: code.Transient}, want: code.Retry},
+		{name: "fatal", err: &code.Error{Category: code.Fatal}, want: code.Abort},
+		{name: "wrapped", err: fmt.Errorf("Failed to update: %w", &code.Error{Category: code.NotFound}), want: code.Skip},
+		{name: "not mapped", err: errors.New("unknown"), want: code.Abort},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := code.Decide(tc.err); got != tc.want {
+				t.Errorf("want %s but got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCategoryOfHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		status int
+		want   code.Category
+	}{
+		{status: http.StatusNotFound, want: code.NotFound},
+		{status: http.StatusUnauthorized, want: code.Unauthenticated},
+		{status: http.StatusForbidden, want: code.Permission},
+		{status: http.StatusTooManyRequests, want: code.Throttled},
+		{status: http.StatusConflict, want: code.Conflict},
+		{status: http.StatusPreconditionFailed, want: code.Conflict},
+		{status: http.StatusBadRequest, want: code.InvalidInput},
+		{status: http.StatusServiceUnavailable, want: code.Transient},
+		{status: http.StatusOK, want: code.Fatal},
+	}
+	for _, tc := range testCases {
+		if got := code.CategoryOfHTTPStatus(tc.status); got != tc.want {
+			t.Errorf("status %d: want %s but got %s", tc.status, tc.want, got)
+		}
+	}
+}
+
+func TestFromAWS(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want code.Category
+	}{
+		{name: "mapped code", err: &smithy.GenericAPIError{Code: "EntityNotFoundException"}, want: code.NotFound},
+		{name: "not found suffix", err: &smithy.GenericAPIError{Code: "DatabaseNotFoundException"}, want: code.NotFound},
+		{name: "throttled", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: code.Throttled},
+		{name: "expired token", err: &smithy.GenericAPIError{Code: "ExpiredTokenException"}, want: code.Unauthenticated},
+		{name: "already exists", err: &smithy.GenericAPIError{Code: "AlreadyExistsException"}, want: code.AlreadyExists},
+		{name: "unknown code", err: &smithy.GenericAPIError{Code: "SomethingWrong"}, want: code.Fatal},
+		{name: "not an api error", err: errors.New("connection reset"), want: code.Fatal},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := code.FromAWS("glue", "GetTable", tc.err)
+			if got := code.CategoryOf(err); got != tc.want {
+				t.Errorf("want %s but got %s", tc.want, got)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("the original error must be unwrapped")
+			}
+		})
+	}
+	if err := code.FromAWS("glue", "GetTable", nil); err != nil {
+		t.Errorf("want nil but got %s", err)
+	}
+}
+
+func TestFromGoogle(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want code.Category
+	}{
+		{name: "http status", err: &googleapi.Error{Code: http.StatusForbidden}, want: code.Permission},
+		{name: "reason", err: &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, want: code.Throttled},
+		{name: "grpc status", err: status.Error(codes.ResourceExhausted, "quota"), want: code.Throttled},
+		{name: "grpc not found", err: status.Error(codes.NotFound, "entry"), want: code.NotFound},
+		{name: "grpc unauthenticated", err: status.Error(codes.Unauthenticated, "token"), want: code.Unauthenticated},
+		{name: "grpc already exists", err: status.Error(codes.AlreadyExists, "tag"), want: code.AlreadyExists},
+		{name: "http unauthorized", err: &googleapi.Error{Code: http.StatusUnauthorized}, want: code.Unauthenticated},
+		{name: "not an api error", err: errors.New("connection reset"), want: code.Fatal},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := code.CategoryOf(code.FromGoogle("bigquery", "GetTableMetadata", tc.err)); got != tc.want {
+				t.Errorf("want %s but got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDoWithRetry(t *testing.T) {
+	testCases := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "succeeds", errs: []error{nil}, wantAttempts: 1},
+		{name: "succeeds after retry", errs: []error{&code.Error{Category: code.Throttled}, nil}, wantAttempts: 2},
+		{name: "gives up", errs: []error{&code.Error{Category: code.Transient}, &code.Error{Category: code.Transient}, &code.Error{Category: code.Transient}}, wantAttempts: 3, wantErr: true},
+		{name: "doesn't retry skip", errs: []error{&code.Error{Category: code.NotFound}}, wantAttempts: 1, wantErr: true},
+		{name: "doesn't retry abort", errs: []error{errors.New("unknown")}, wantAttempts: 1, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := code.DoWithRetry(3, 0, func() error {
+				err := tc.errs[attempts]
+				attempts++
+				return err
+			})
+			if attempts != tc.wantAttempts {
+				t.Errorf("want %d attempts but got %d", tc.wantAttempts, attempts)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v but got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package code
+
+import (
+	"errors"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodeCategories maps the gRPC status codes of the Google Cloud APIs to the categories.
+var grpcCodeCategories = map[codes.Code]Category{
+	codes.NotFound:           NotFound,
+	codes.PermissionDenied:   Permission,
+	codes.Unauthenticated:    Unauthenticated,
+	codes.ResourceExhausted:  Throttled,
+	codes.Aborted:            Conflict,
+	codes.AlreadyExists:      AlreadyExists,
+	codes.FailedPrecondition: InvalidInput,
+	codes.InvalidArgument:    InvalidInput,
+	codes.OutOfRange:         InvalidInput,
+	codes.Unavailable:        Transient,
+	codes.DeadlineExceeded:   Transient,
+	codes.Internal:           Transient,
+}
+
+// googleReasonCategories maps the reasons of the BigQuery REST API errors, which are more specific than the status codes.
+var googleReasonCategories = map[string]Category{
+	"notFound":                 NotFound,
+	"accessDenied":             Permission,
+	"rateLimitExceeded":        Throttled,
+	"quotaExceeded":            Throttled,
+	"backendError":             Transient,
+	"internalError":            Transient,
+	"invalid":                  InvalidInput,
+	"invalidQuery":             InvalidInput,
+	"conditionNotMet":          Conflict,
+	"resourceInUse":            Conflict,
+	"responseTooLarge":         InvalidInput,
+	"billingNotEnabled":        Permission,
+	"billingTierLimitExceeded": Permission,
+}
+
+// FromGoogle maps the error of the Google Cloud client libraries into Error. It returns nil for nil.
+func FromGoogle(service, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{
+		Service:   service,
+		Operation: operation,
+		Category:  Fatal,
+		Message:   err.Error(),
+		Err:       err,
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		e.Status = apiErr.Code
+		e.Category = CategoryOfHTTPStatus(apiErr.Code)
+		if len(apiErr.Errors) > 0 {
+			e.Code = apiErr.Errors[0].Reason
+			if category, ok := googleReasonCategories[e.Code]; ok {
+				e.Category = category
+			}
+		}
+		return e
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		e.Code = s.Code().String()
+		e.Message = s.Message()
+		if category, ok := grpcCodeCategories[s.Code()]; ok {
+			e.Category = category
+		}
+	}
+	return e
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"sync"
+
+	"quollio-reverse-agent/common/logger"
+)
+
+const (
+	INFO    = "INFO"
+	WARNING = "WARNING"
+	ALERT   = "ALERT"
+)
+
+// Entry is a single event that should be shown to operators after the run.
+type Entry struct {
+	Level   string
+	Service string
+	Target  string
+	Field   string
+	Message string
+}
+
+// Report collects the entries of a run. The methods are safe to call on a nil Report.
+type Report struct {
+	mu      sync.Mutex
+	Entries []Entry
+}
+
+func NewReport() *Report {
+	return &Report{}
+}
+
+func (r *Report) Add(level, service, target, field, message string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Entries = append(r.Entries, Entry{
+		Level:   level,
+		Service: service,
+		Target:  target,
+		Field:   field,
+		Message: message,
+	})
+}
+
+func (r *Report) CountByLevel(level string) int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, entry := range r.Entries {
+		if entry.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Report) Print(logger *logger.BuiltinLogger) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	logger.Info("Report: %d entries", len(r.Entries))
+	for _, entry := range r.Entries {
+		switch entry.Level {
+		case ALERT:
+			logger.Error("[REPORT][%s] %s %s %s: %s", entry.Level, entry.Service, entry.Target, entry.Field, entry.Message)
+		case WARNING:
+			logger.Warning("[REPORT][%s] %s %s %s: %s", entry.Level, entry.Service, entry.Target, entry.Field, entry.Message)
+		default:
+			logger.Info("[REPORT][%s] %s %s %s: %s", entry.Level, entry.Service, entry.Target, entry.Field, entry.Message)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package report_test
+
+import (
+	"quollio-reverse-agent/common/report"
+	"testing"
+)
+
+func TestReportAdd(t *testing.T) {
+	r := report.NewReport()
+	r.Add(report.WARNING, "athena", "db.table", "comment", "truncated")
+	r.Add(report.ALERT, "athena", "db.table", "", "rolled back")
+	r.Add(report.WARNING, "athena", "db.table2", "comment", "truncated")
+	if len(r.Entries) != 3 {
+		t.Errorf("want 3 entries but got %d", len(r.Entries))
+	}
+	if r.CountByLevel(report.WARNING) != 2 {
+		t.Errorf("want 2 warnings but got %d", r.CountByLevel(report.WARNING))
+	}
+
+	var nilReport *report.Report
+	nilReport.Add(report.INFO, "athena", "db.table", "", "nil report must be ignored")
+	if nilReport.CountByLevel(report.INFO) != 0 {
+		t.Errorf("nil report must have no entries")
+	}
+}
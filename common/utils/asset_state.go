@@ -0,0 +1,100 @@
+package utils
+
+import "fmt"
+
+const (
+	AssetStateUpdate    = "UPDATE"    // the asset is updated in the same way as an active asset.
+	AssetStateSkip      = "SKIP"      // the asset is not updated.
+	AssetStateClear     = "CLEAR"     // the description written by the agent is cleared.
+	AssetStateDeprecate = "DEPRECATE" // the asset is marked as deprecated in the target.
+
+	DefaultDeprecationNotice = "【DEPRECATED】" // Default notice put in front of the description of deprecated assets.
+)
+
+// AssetStatePolicy decides how assets that are lost or archived in QDIC are reflected in the targets.
+type AssetStatePolicy struct {
+	LostAssetPolicy     string
+	ArchivedAssetPolicy string
+	DeprecationNotice   string
+}
+
+// DefaultAssetStatePolicy returns the policy used when no policy is given.
+func DefaultAssetStatePolicy() AssetStatePolicy {
+	return AssetStatePolicy{
+		LostAssetPolicy:     AssetStateSkip,   // MEMO: lost assets have been skipped since the first release.
+		ArchivedAssetPolicy: AssetStateUpdate, // MEMO: archived assets have been updated since the first release.
+		DeprecationNotice:   DefaultDeprecationNotice,
+	}
+}
+
+// NewAssetStatePolicy returns the policy. An empty value falls back to the default, and an invalid value is an error.
+func NewAssetStatePolicy(lostAssetPolicy, archivedAssetPolicy, deprecationNotice string) (AssetStatePolicy, error) {
+	policy := DefaultAssetStatePolicy()
+	var err error
+	if lostAssetPolicy != "" {
+		policy.LostAssetPolicy, err = parseAssetState(lostAssetPolicy)
+		if err != nil {
+			return AssetStatePolicy{}, fmt.Errorf("invalid lost asset policy. %s", err)
+		}
+	}
+	if archivedAssetPolicy != "" {
+		policy.ArchivedAssetPolicy, err = parseAssetState(archivedAssetPolicy)
+		if err != nil {
+			return AssetStatePolicy{}, fmt.Errorf("invalid archived asset policy. %s", err)
+		}
+	}
+	if deprecationNotice != "" {
+		policy.DeprecationNotice = deprecationNotice
+	}
+	return policy, nil
+}
+
+func parseAssetState(state string) (string, error) {
+	switch state {
+	case AssetStateUpdate, AssetStateSkip, AssetStateClear, AssetStateDeprecate:
+		return state, nil
+	default:
+		return "", fmt.Errorf("%s must be %s, %s, %s or %s", state, AssetStateUpdate, AssetStateSkip, AssetStateClear, AssetStateDeprecate)
+	}
+}
+
+// GetAction returns the action for the asset. The lost policy takes precedence over the archived one.
+func (p AssetStatePolicy) GetAction(isLost, isArchived bool) string {
+	if isLost {
+		if p.LostAssetPolicy == "" {
+			return AssetStateSkip
+		}
+		return p.LostAssetPolicy
+	}
+	if isArchived && p.ArchivedAssetPolicy != "" {
+		return p.ArchivedAssetPolicy
+	}
+	return AssetStateUpdate
+}
+
+// GenDescription returns the description that should be written to the target for CLEAR and DEPRECATE actions.
+// qdcDesc is rendered into the format of the target, and the current description is compared by its visible text.
+// The second return value is false when the target should be left as it is.
+func (p AssetStatePolicy) GenDescription(action, prefixForUpdate, overwriteMode, format, currentDesc, qdcDesc string) (string, bool) {
+	isOwnedByAgent := HasPrefixInFormat(currentDesc, prefixForUpdate, format)
+	switch action {
+	case AssetStateClear:
+		// MEMO: Only descriptions written by the agent are cleared.
+		if currentDesc != "" && isOwnedByAgent {
+			return "", true
+		}
+		return "", false
+	case AssetStateDeprecate:
+		// MEMO: The notice is put after the rendering, so that the first block of qdcDesc such as a heading or a list is rendered.
+		descWithPrefix := AddPrefixInFormat(prefixForUpdate, addNoticeInFormat(p.DeprecationNotice, ConvertMarkdown(qdcDesc, format), format), format)
+		if IsSameInFormat(currentDesc, descWithPrefix, format) {
+			return "", false
+		}
+		if overwriteMode == OverwriteAll || currentDesc == "" || isOwnedByAgent {
+			return descWithPrefix, true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
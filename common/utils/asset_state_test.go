@@ -0,0 +1,208 @@
+package utils_test
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"testing"
+)
+
+func TestNewAssetStatePolicy(t *testing.T) {
+	policy, err := utils.NewAssetStatePolicy(utils.AssetStateClear, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := utils.AssetStatePolicy{LostAssetPolicy: utils.AssetStateClear, ArchivedAssetPolicy: utils.AssetStateUpdate, DeprecationNotice: utils.DefaultDeprecationNotice}
+	if policy != want {
+		t.Errorf("want %+v but got %+v", want, policy)
+	}
+	if _, err := utils.NewAssetStatePolicy("REMOVE", "", ""); err == nil {
+		t.Errorf("want error for the invalid lost asset policy")
+	}
+	if _, err := utils.NewAssetStatePolicy("", "clear", ""); err == nil {
+		t.Errorf("want error for the invalid archived asset policy")
+	}
+}
+
+func TestAssetStatePolicyGetAction(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     utils.AssetStatePolicy
+		isLost     bool
+		isArchived bool
+		want       string
+	}{
+		{
+			name:   "default policy skips lost assets",
+			policy: utils.DefaultAssetStatePolicy(),
+			isLost: true,
+			want:   utils.AssetStateSkip,
+		},
+		{
+			name:       "default policy updates archived assets",
+			policy:     utils.DefaultAssetStatePolicy(),
+			isArchived: true,
+			want:       utils.AssetStateUpdate,
+		},
+		{
+			name:       "lost policy takes precedence",
+			policy:     utils.AssetStatePolicy{LostAssetPolicy: utils.AssetStateClear, ArchivedAssetPolicy: utils.AssetStateDeprecate},
+			isLost:     true,
+			isArchived: true,
+			want:       utils.AssetStateClear,
+		},
+		{
+			name:       "archived policy",
+			policy:     utils.AssetStatePolicy{LostAssetPolicy: utils.AssetStateClear, ArchivedAssetPolicy: utils.AssetStateDeprecate},
+			isArchived: true,
+			want:       utils.AssetStateDeprecate,
+		},
+		{
+			name:   "zero value skips lost assets",
+			policy: utils.AssetStatePolicy{},
+			isLost: true,
+			want:   utils.AssetStateSkip,
+		},
+		{
+			name:   "active asset",
+			policy: utils.AssetStatePolicy{LostAssetPolicy: utils.AssetStateClear, ArchivedAssetPolicy: utils.AssetStateClear},
+			want:   utils.AssetStateUpdate,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tt.policy.GetAction(tt.isLost, tt.isArchived)
+			if res != tt.want {
+				t.Errorf("want %s but got %s", tt.want, res)
+			}
+		})
+	}
+}
+
+func TestAssetStatePolicyGenDescription(t *testing.T) {
+	policy := utils.DefaultAssetStatePolicy()
+	testCases := []struct {
+		name          string
+		action        string
+		overwriteMode string
+		format        string
+		currentDesc   string
+		qdcDesc       string
+		want          string
+		wantUpdate    bool
+	}{
+		{
+			name:          "clear the description written by the agent",
+			action:        utils.AssetStateClear,
+			overwriteMode: utils.OverwriteIfEmpty,
+			currentDesc:   "【QDIC】old-description",
+			want:          "",
+			wantUpdate:    true,
+		},
+		{
+			name:          "don't clear the description written by others",
+			action:        utils.AssetStateClear,
+			overwriteMode: utils.OverwriteAll,
+			currentDesc:   "description by others",
+			want:          "",
+			wantUpdate:    false,
+		},
+		{
+			name:          "don't clear the empty description",
+			action:        utils.AssetStateClear,
+			overwriteMode: utils.OverwriteIfEmpty,
+			currentDesc:   "",
+			want:          "",
+			wantUpdate:    false,
+		},
+		{
+			name:          "deprecate the empty description",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteIfEmpty,
+			currentDesc:   "",
+			qdcDesc:       "qdc-description",
+			want:          "【QDIC】【DEPRECATED】\nqdc-description",
+			wantUpdate:    true,
+		},
+		{
+			name:          "already deprecated",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteAll,
+			currentDesc:   "【QDIC】【DEPRECATED】\nqdc-description",
+			qdcDesc:       "qdc-description",
+			want:          "",
+			wantUpdate:    false,
+		},
+		{
+			name:          "don't deprecate the description written by others",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteIfEmpty,
+			currentDesc:   "description by others",
+			qdcDesc:       "qdc-description",
+			want:          "",
+			wantUpdate:    false,
+		},
+		{
+			name:          "deprecate the description written by others with OVERWRITE_ALL",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteAll,
+			currentDesc:   "description by others",
+			qdcDesc:       "qdc-description",
+			want:          "【QDIC】【DEPRECATED】\nqdc-description",
+			wantUpdate:    true,
+		},
+		{
+			name:          "clear the html description written by the agent",
+			action:        utils.AssetStateClear,
+			overwriteMode: utils.OverwriteIfEmpty,
+			format:        utils.FormatHTML,
+			currentDesc:   "<p>【QDIC】old-description</p>",
+			want:          "",
+			wantUpdate:    true,
+		},
+		{
+			name:          "already deprecated in html",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteIfEmpty,
+			format:        utils.FormatHTML,
+			currentDesc:   "<p>【QDIC】【DEPRECATED】</p><p><strong>qdc</strong>-description</p>",
+			qdcDesc:       "**qdc**-description",
+			want:          "",
+			wantUpdate:    false,
+		},
+		{
+			name:          "deprecate the description that starts with a heading",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteIfEmpty,
+			currentDesc:   "",
+			qdcDesc:       "# Orders\nqdc-description",
+			want:          "【QDIC】【DEPRECATED】\nOrders\n\nqdc-description",
+			wantUpdate:    true,
+		},
+		{
+			name:          "deprecate the html description that starts with a list",
+			action:        utils.AssetStateDeprecate,
+			overwriteMode: utils.OverwriteIfEmpty,
+			format:        utils.FormatHTML,
+			currentDesc:   "",
+			qdcDesc:       "- order_id\n- customer_id",
+			want:          "<p>【QDIC】【DEPRECATED】</p><ul><li>order_id</li><li>customer_id</li></ul>",
+			wantUpdate:    true,
+		},
+		{
+			name:          "skip",
+			action:        utils.AssetStateSkip,
+			overwriteMode: utils.OverwriteAll,
+			currentDesc:   "【QDIC】old-description",
+			qdcDesc:       "qdc-description",
+			want:          "",
+			wantUpdate:    false,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, shouldUpdate := policy.GenDescription(tt.action, "【QDIC】", tt.overwriteMode, tt.format, tt.currentDesc, tt.qdcDesc)
+			if res != tt.want || shouldUpdate != tt.wantUpdate {
+				t.Errorf("want %s, %v but got %s, %v", tt.want, tt.wantUpdate, res, shouldUpdate)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+const (
+	FormatPlainText = "PLAIN_TEXT" // Glue, BigQuery and Denodo VDP.
+	FormatHTML      = "HTML"       // Dataplex overview.
+	FormatRichText  = "RICH_TEXT"  // Denodo Data Catalog. It's rendered as HTML.
+)
+
+var (
+	htmlDangerousBlockRegexp = regexp.MustCompile(`(?is)<(script|style|iframe|object|embed)\b.*?</(script|style|iframe|object|embed)\s*>`)
+	htmlLineBreakRegexp      = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|tr|blockquote|pre)\s*>`)
+	htmlTagRegexp            = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^>]*)?/?>|<!--.*?-->`)
+	htmlElementRegexp        = regexp.MustCompile(`(?i)</?(a|abbr|b|blockquote|br|code|dd|del|div|dl|dt|em|embed|font|h[1-6]|hr|i|iframe|img|ins|li|object|ol|p|pre|s|script|small|span|strong|style|sub|sup|table|tbody|td|th|thead|tr|u|ul)(\s[^>]*)?/?>|<!--`)
+	markdownHeadingRegexp    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownUnorderedRegexp  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	markdownOrderedRegexp    = regexp.MustCompile(`^(\d{1,9})[.)]\s+(.*)$`)
+	markdownQuoteRegexp      = regexp.MustCompile(`^>\s?(.*)$`)
+	markdownInlineRegexp     = regexp.MustCompile("\\\\[\\\\`*_\\[\\]()#+\\-.!<>]|`[^`]+`|!?\\[[^\\]]*\\]\\((?:[^()\\s]|\\([^()\\s]*\\))*\\)|<(?:https?://|mailto:)[^>\\s]+>|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*")
+	markdownLinkRegexp       = regexp.MustCompile(`^(!?)\[([^\]]*)\]\(((?:[^()\s]|\([^()\s]*\))*)\)$`)
+)
+
+// GetFieldFormat returns the native format of the target field.
+func GetFieldFormat(field string) string {
+	switch field {
+	case FieldDataplexTableOverview:
+		return FormatHTML
+	case FieldDenodoDataCatalogDatabaseDescription, FieldDenodoDataCatalogViewDescription, FieldDenodoDataCatalogFieldDescription:
+		return FormatRichText
+	default:
+		return FormatPlainText
+	}
+}
+
+func isHTMLFormat(format string) bool {
+	return format == FormatHTML || format == FormatRichText
+}
+
+// RenderDescription converts the QDIC description written in Markdown into the format and puts the prefix at the beginning.
+func RenderDescription(prefixForUpdate, markdown, format string) string {
+	return AddPrefixInFormat(prefixForUpdate, ConvertMarkdown(markdown, format), format)
+}
+
+// AddPrefixInFormat puts the prefix at the beginning of the text, so that the prefix is visible in the target.
+func AddPrefixInFormat(prefixForUpdate, s, format string) string {
+	if !isHTMLFormat(format) {
+		return AddPrefixToStringIfNotHas(prefixForUpdate, s)
+	}
+	if HasPrefixInFormat(s, prefixForUpdate, format) {
+		return s
+	}
+	escapedPrefix := html.EscapeString(prefixForUpdate)
+	if strings.HasPrefix(s, "<p>") {
+		return "<p>" + escapedPrefix + strings.TrimPrefix(s, "<p>")
+	}
+	return "<p>" + escapedPrefix + "</p>" + s
+}
+
+// addNoticeInFormat puts the notice before the text as its own line in plain text, or as its own paragraph in HTML.
+func addNoticeInFormat(notice, s, format string) string {
+	if notice == "" {
+		return s
+	}
+	if isHTMLFormat(format) {
+		return "<p>" + html.EscapeString(notice) + "</p>" + s
+	}
+	if s == "" {
+		return notice
+	}
+	return notice + "\n" + s
+}
+
+// HasPrefixInFormat checks the prefix on the text that is visible in the target.
+// MEMO: Dataplex and Denodo Data Catalog may wrap the description with tags such as `<p>`.
+func HasPrefixInFormat(s, prefixForUpdate, format string) bool {
+	return strings.HasPrefix(strings.TrimSpace(ToPlainText(s, format)), prefixForUpdate)
+}
+
+// IsSameInFormat compares two descriptions by the text that is visible in the target.
+func IsSameInFormat(a, b, format string) bool {
+	if !isHTMLFormat(format) {
+		return a == b
+	}
+	return strings.TrimSpace(ToPlainText(a, format)) == strings.TrimSpace(ToPlainText(b, format))
+}
+
+// ToPlainText returns the visible text of the description stored in the format.
+func ToPlainText(s, format string) string {
+	if !isHTMLFormat(format) {
+		return s
+	}
+	return stripHTML(s)
+}
+
+// containsHTML returns true if the text has HTML elements or comments.
+// Literal texts such as `<min, max>` or `<x>` are not regarded as HTML.
+func containsHTML(s string) bool {
+	return htmlElementRegexp.MatchString(s)
+}
+
+// stripHTML drops tags and dangerous elements, and keeps the text and line breaks.
+func stripHTML(s string) string {
+	s = htmlDangerousBlockRegexp.ReplaceAllString(s, "")
+	s = htmlLineBreakRegexp.ReplaceAllString(s, "\n")
+	s = htmlTagRegexp.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+// ConvertMarkdown renders the Markdown into the format.
+// Raw HTML in the input is sanitized. Only the text of it is kept.
+// For the plain text formats, the input is sanitized only when it contains HTML, so that literal `<` and `&` are kept as they are.
+// Supported syntax: headings, paragraphs, lists, quotes, fenced code blocks, links, images, emphasis and inline code.
+func ConvertMarkdown(markdown, format string) string {
+	if markdown == "" {
+		return ""
+	}
+	if isHTMLFormat(format) || containsHTML(markdown) {
+		markdown = stripHTML(markdown)
+	}
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	r := markdownRenderer{isHTML: isHTMLFormat(format)}
+	inCodeBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			r.closeBlock()
+			if inCodeBlock {
+				r.writeCode()
+			}
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			r.code = append(r.code, line)
+			continue
+		}
+		if trimmed == "" {
+			r.closeBlock()
+			continue
+		}
+		if m := markdownHeadingRegexp.FindStringSubmatch(trimmed); m != nil {
+			r.closeBlock()
+			r.writeHeading(len(m[1]), m[2])
+		} else if m := markdownUnorderedRegexp.FindStringSubmatch(trimmed); m != nil {
+			r.writeListItem("ul", "-", m[1])
+		} else if m := markdownOrderedRegexp.FindStringSubmatch(trimmed); m != nil {
+			r.writeListItem("ol", m[1]+".", m[2])
+		} else if m := markdownQuoteRegexp.FindStringSubmatch(trimmed); m != nil {
+			r.writeBlockLine("blockquote", m[1])
+		} else {
+			r.writeBlockLine("p", trimmed)
+		}
+	}
+	if inCodeBlock {
+		r.writeCode()
+	}
+	r.closeBlock()
+	return strings.TrimSpace(r.sb.String())
+}
+
+type markdownRenderer struct {
+	isHTML     bool
+	sb         strings.Builder
+	block      string // "p", "blockquote", "ul" or "ol". empty if no block is open.
+	blockLines []string
+	code       []string
+}
+
+func (r *markdownRenderer) closeBlock() {
+	switch r.block {
+	case "":
+		return
+	case "ul", "ol":
+		if r.isHTML {
+			r.sb.WriteString(fmt.Sprintf("</%s>", r.block))
+		}
+	default:
+		if r.isHTML {
+			r.sb.WriteString(fmt.Sprintf("<%s>%s</%s>", r.block, strings.Join(r.blockLines, "<br>"), r.block))
+		} else {
+			r.writePlainBlock(strings.Join(r.blockLines, "\n"))
+		}
+	}
+	r.block = ""
+	r.blockLines = nil
+}
+
+func (r *markdownRenderer) writePlainBlock(s string) {
+	if r.sb.Len() > 0 {
+		r.sb.WriteString("\n\n")
+	}
+	r.sb.WriteString(s)
+}
+
+func (r *markdownRenderer) writeHeading(level int, text string) {
+	if r.isHTML {
+		r.sb.WriteString(fmt.Sprintf("<h%d>%s</h%d>", level, r.renderInline(text), level))
+		return
+	}
+	r.writePlainBlock(r.renderInline(text))
+}
+
+func (r *markdownRenderer) writeListItem(listType, marker, text string) {
+	if r.block != listType {
+		r.closeBlock()
+		r.block = listType
+		if r.isHTML {
+			r.sb.WriteString(fmt.Sprintf("<%s>", listType))
+		} else if r.sb.Len() > 0 {
+			r.sb.WriteString("\n\n")
+		}
+	} else if !r.isHTML {
+		r.sb.WriteString("\n")
+	}
+	if r.isHTML {
+		r.sb.WriteString(fmt.Sprintf("<li>%s</li>", r.renderInline(text)))
+		return
+	}
+	r.sb.WriteString(fmt.Sprintf("%s %s", marker, r.renderInline(text)))
+}
+
+func (r *markdownRenderer) writeBlockLine(block, text string) {
+	if r.block != block {
+		r.closeBlock()
+		r.block = block
+	}
+	r.blockLines = append(r.blockLines, r.renderInline(text))
+}
+
+func (r *markdownRenderer) writeCode() {
+	code := strings.Join(r.code, "\n")
+	r.code = nil
+	if r.isHTML {
+		r.sb.WriteString(fmt.Sprintf("<pre><code>%s</code></pre>", html.EscapeString(code)))
+		return
+	}
+	r.writePlainBlock(code)
+}
+
+func (r *markdownRenderer) renderInline(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range markdownInlineRegexp.FindAllStringIndex(text, -1) {
+		sb.WriteString(r.text(text[last:loc[0]]))
+		sb.WriteString(r.renderInlineToken(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	sb.WriteString(r.text(text[last:]))
+	return sb.String()
+}
+
+func (r *markdownRenderer) renderInlineToken(token string) string {
+	switch {
+	case strings.HasPrefix(token, "\\"):
+		return r.text(token[1:])
+	case strings.HasPrefix(token, "`"):
+		return r.wrap("code", r.text(strings.Trim(token, "`")))
+	case strings.HasPrefix(token, "**"), strings.HasPrefix(token, "__"):
+		return r.wrap("strong", r.renderInline(token[2:len(token)-2]))
+	case strings.HasPrefix(token, "*"):
+		return r.wrap("em", r.renderInline(token[1:len(token)-1]))
+	case strings.HasPrefix(token, "<"):
+		url := strings.Trim(token, "<>")
+		return r.link(url, r.text(url))
+	}
+	m := markdownLinkRegexp.FindStringSubmatch(token)
+	if m == nil {
+		return r.text(token)
+	}
+	label, url := m[2], m[3]
+	if m[1] == "!" {
+		// MEMO: Images are not rendered in the targets. The alternative text is kept.
+		return r.text(label)
+	}
+	if label == "" {
+		label = url
+	}
+	if !r.isHTML && label != url && url != "" {
+		return fmt.Sprintf("%s (%s)", r.renderInline(label), url)
+	}
+	return r.link(url, r.renderInline(label))
+}
+
+func (r *markdownRenderer) link(url, label string) string {
+	if !r.isHTML || !isSafeURL(url) {
+		return label
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), label)
+}
+
+func (r *markdownRenderer) wrap(tag, s string) string {
+	if !r.isHTML {
+		return s
+	}
+	return fmt.Sprintf("<%s>%s</%s>", tag, s, tag)
+}
+
+func (r *markdownRenderer) text(s string) string {
+	if !r.isHTML {
+		return s
+	}
+	return html.EscapeString(s)
+}
+
+func isSafeURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "mailto:")
+}
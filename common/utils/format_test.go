@@ -0,0 +1,118 @@
+package utils_test
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"testing"
+)
+
+func TestConvertMarkdown(t *testing.T) {
+	markdown := "# 注文\n**注文**の明細です。\n詳細は[設計書](https://example.com/docs)を参照。\n\n- order_id\n- customer_id\n\n1. 作成\n2. 更新\n\n<script>alert(1)</script><b>raw</b> & `a<b`"
+	testCases := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "plain text",
+			format: utils.FormatPlainText,
+			want:   "注文\n\n注文の明細です。\n詳細は設計書 (https://example.com/docs)を参照。\n\n- order_id\n- customer_id\n\n1. 作成\n2. 更新\n\nraw & a<b",
+		},
+		{
+			name:   "html",
+			format: utils.FormatHTML,
+			want:   `<h1>注文</h1><p><strong>注文</strong>の明細です。<br>詳細は<a href="https://example.com/docs">設計書</a>を参照。</p><ul><li>order_id</li><li>customer_id</li></ul><ol><li>作成</li><li>更新</li></ol><p>raw &amp; <code>a&lt;b</code></p>`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := utils.ConvertMarkdown(markdown, tt.format)
+			if res != tt.want {
+				t.Errorf("want %q but got %q.", tt.want, res)
+			}
+		})
+	}
+}
+
+func TestConvertMarkdownPlainTextLiteral(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{name: "angle brackets", markdown: "values in <min, max>", want: "values in <min, max>"},
+		{name: "unknown tag like text", markdown: "type is <x>", want: "type is <x>"},
+		{name: "entity", markdown: "AT&amp;T", want: "AT&amp;T"},
+		{name: "html", markdown: "<b>AT&amp;T</b>", want: "AT&T"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := utils.ConvertMarkdown(tt.markdown, utils.FormatPlainText)
+			if res != tt.want {
+				t.Errorf("want %q but got %q.", tt.want, res)
+			}
+		})
+	}
+}
+
+func TestConvertMarkdownUnsafeLink(t *testing.T) {
+	res := utils.ConvertMarkdown("[click](javascript:alert(1)) snake_case_name", utils.FormatRichText)
+	want := "<p>click snake_case_name</p>"
+	if res != want {
+		t.Errorf("want %q but got %q.", want, res)
+	}
+}
+
+func TestRenderDescription(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		format   string
+		want     string
+	}{
+		{
+			name:     "plain text",
+			markdown: "*説明*",
+			format:   utils.FormatPlainText,
+			want:     "【QDIC】説明",
+		},
+		{
+			name:     "paragraph in html",
+			markdown: "*説明*",
+			format:   utils.FormatHTML,
+			want:     "<p>【QDIC】<em>説明</em></p>",
+		},
+		{
+			name:     "heading in html",
+			markdown: "## 説明",
+			format:   utils.FormatRichText,
+			want:     "<p>【QDIC】</p><h2>説明</h2>",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := utils.RenderDescription("【QDIC】", tt.markdown, tt.format)
+			if res != tt.want {
+				t.Errorf("want %q but got %q.", tt.want, res)
+			}
+		})
+	}
+}
+
+func TestHasPrefixInFormat(t *testing.T) {
+	testCases := []struct {
+		input  string
+		format string
+		want   bool
+	}{
+		{input: "<p>【QDIC】説明</p>", format: utils.FormatHTML, want: true},
+		{input: "<div><p>\n【QDIC】説明</p></div>", format: utils.FormatRichText, want: true},
+		{input: "<p>【QDIC】説明</p>", format: utils.FormatPlainText, want: false},
+		{input: "<p>説明【QDIC】</p>", format: utils.FormatHTML, want: false},
+	}
+	for _, tt := range testCases {
+		res := utils.HasPrefixInFormat(tt.input, "【QDIC】", tt.format)
+		if res != tt.want {
+			t.Errorf("want %v but got %v. input: %s format: %s", tt.want, res, tt.input, tt.format)
+		}
+	}
+}
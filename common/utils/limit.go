@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	LengthUnitCharacter = "CHARACTER" // Unicode code points. Japanese letters are counted as one character.
+	LengthUnitUTF16     = "UTF16"     // UTF-16 code units, used by AWS APIs.
+	LengthUnitByte      = "BYTE"      // UTF-8 bytes.
+
+	Ellipsis = "…"
+)
+
+const (
+	FieldGlueDatabaseDescription              = "glue.database.description"
+	FieldGlueTableDescription                 = "glue.table.description"
+	FieldGlueColumnComment                    = "glue.column.comment"
+	FieldBigQueryDatasetDescription           = "bigquery.dataset.description"
+	FieldBigQueryColumnDescription            = "bigquery.column.description"
+	FieldDataplexTableOverview                = "dataplex.table.overview"
+	FieldDenodoVdpDatabaseDescription         = "denodo.vdp.database.description"
+	FieldDenodoVdpViewDescription             = "denodo.vdp.view.description"
+	FieldDenodoVdpColumnDescription           = "denodo.vdp.column.description"
+	FieldDenodoDataCatalogDatabaseDescription = "denodo.datacatalog.database.description"
+	FieldDenodoDataCatalogViewDescription     = "denodo.datacatalog.view.description"
+	FieldDenodoDataCatalogFieldDescription    = "denodo.datacatalog.field.description"
+)
+
+// FieldLimit is the maximum length of a target field. MaxLength 0 means no limit.
+type FieldLimit struct {
+	MaxLength int
+	Unit      string
+}
+
+// DefaultFieldLimits returns the limits documented by each service.
+// MEMO: Denodo doesn't document the limits. Set DESCRIPTION_LENGTH_LIMITS if your server rejects long descriptions.
+func DefaultFieldLimits() map[string]FieldLimit {
+	return map[string]FieldLimit{
+		FieldGlueDatabaseDescription:              {MaxLength: 2048, Unit: LengthUnitUTF16},
+		FieldGlueTableDescription:                 {MaxLength: 2048, Unit: LengthUnitUTF16},
+		FieldGlueColumnComment:                    {MaxLength: 255, Unit: LengthUnitUTF16},
+		FieldBigQueryDatasetDescription:           {MaxLength: 16384, Unit: LengthUnitCharacter},
+		FieldBigQueryColumnDescription:            {MaxLength: 1024, Unit: LengthUnitCharacter},
+		FieldDataplexTableOverview:                {MaxLength: 10 * 1024 * 1024, Unit: LengthUnitByte},
+		FieldDenodoVdpDatabaseDescription:         {MaxLength: 0, Unit: LengthUnitCharacter},
+		FieldDenodoVdpViewDescription:             {MaxLength: 0, Unit: LengthUnitCharacter},
+		FieldDenodoVdpColumnDescription:           {MaxLength: 0, Unit: LengthUnitCharacter},
+		FieldDenodoDataCatalogDatabaseDescription: {MaxLength: 0, Unit: LengthUnitCharacter},
+		FieldDenodoDataCatalogViewDescription:     {MaxLength: 0, Unit: LengthUnitCharacter},
+		FieldDenodoDataCatalogFieldDescription:    {MaxLength: 0, Unit: LengthUnitCharacter},
+	}
+}
+
+// DescriptionLimiter truncates descriptions that exceed the limit of the target field.
+type DescriptionLimiter struct {
+	Limits           map[string]FieldLimit
+	AssetURLTemplate string
+}
+
+// NewDescriptionLimiter builds a limiter from the default limits.
+// limitOverrides is a whitespace separated list such as `glue.column.comment=200 denodo.vdp.view.description=4000`.
+// assetURLTemplate is a QDIC URL with `{id}` placeholder, which is appended to truncated descriptions.
+func NewDescriptionLimiter(limitOverrides, assetURLTemplate string) (DescriptionLimiter, error) {
+	limits := DefaultFieldLimits()
+	for _, override := range ConvertStringToListByWhiteSpace(limitOverrides) {
+		kv := strings.SplitN(override, "=", 2)
+		if len(kv) != 2 {
+			return DescriptionLimiter{}, fmt.Errorf("invalid limit %s. use <field>=<length>", override)
+		}
+		maxLength, err := strconv.Atoi(kv[1])
+		if err != nil || maxLength < 0 {
+			return DescriptionLimiter{}, fmt.Errorf("invalid length of %s: %s", kv[0], kv[1])
+		}
+		limit, ok := limits[kv[0]]
+		if !ok {
+			return DescriptionLimiter{}, fmt.Errorf("unknown field %s", kv[0])
+		}
+		limit.MaxLength = maxLength
+		limits[kv[0]] = limit
+	}
+	return DescriptionLimiter{
+		Limits:           limits,
+		AssetURLTemplate: assetURLTemplate,
+	}, nil
+}
+
+// Fit returns the description truncated to the limit of the field, and whether it was truncated.
+func (l DescriptionLimiter) Fit(field, desc, assetID string) (string, bool) {
+	limit, ok := l.Limits[field]
+	if !ok || limit.MaxLength <= 0 || CountLength(desc, limit.Unit) <= limit.MaxLength {
+		return desc, false
+	}
+	isHTML := isHTMLFormat(GetFieldFormat(field))
+	if l.AssetURLTemplate != "" && assetID != "" {
+		assetURL := GenAssetURL(l.AssetURLTemplate, assetID)
+		suffix := Ellipsis + " " + assetURL
+		if isHTML {
+			suffix = fmt.Sprintf(`%s <a href="%s">%s</a>`, Ellipsis, html.EscapeString(assetURL), html.EscapeString(assetURL))
+		}
+		// MEMO: Keep at least some of the description. Otherwise the link is dropped.
+		if CountLength(suffix, limit.Unit)*2 <= limit.MaxLength {
+			return truncateDescription(desc, limit, suffix, isHTML), true
+		}
+	}
+	return truncateDescription(desc, limit, Ellipsis, isHTML), true
+}
+
+func truncateDescription(desc string, limit FieldLimit, suffix string, isHTML bool) string {
+	truncated := strings.TrimSuffix(TruncateString(desc, limit, suffix), suffix)
+	if isHTML {
+		truncated = trimBrokenMarkup(truncated)
+	}
+	return truncated + suffix
+}
+
+// trimBrokenMarkup drops a tag or an entity cut in the middle. Unclosed elements are closed by the renderer of the target.
+func trimBrokenMarkup(s string) string {
+	if i := strings.LastIndex(s, "<"); i > strings.LastIndex(s, ">") {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "&"); i > strings.LastIndex(s, ";") {
+		s = s[:i]
+	}
+	return s
+}
+
+func GenAssetURL(assetURLTemplate, assetID string) string {
+	return strings.ReplaceAll(assetURLTemplate, "{id}", assetID)
+}
+
+func CountLength(s, unit string) int {
+	switch unit {
+	case LengthUnitByte:
+		return len(s)
+	case LengthUnitUTF16:
+		return len(utf16.Encode([]rune(s)))
+	default:
+		return utf8.RuneCountInString(s)
+	}
+}
+
+// TruncateString cuts s so that s + suffix fits into the limit.
+// It never splits a combining character sequence such as a dakuten or a variation selector from its base letter.
+func TruncateString(s string, limit FieldLimit, suffix string) string {
+	if CountLength(s, limit.Unit) <= limit.MaxLength {
+		return s
+	}
+	budget := limit.MaxLength - CountLength(suffix, limit.Unit)
+	if budget <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	end := 0
+	used := 0
+	for end < len(runes) {
+		size := CountLength(string(runes[end]), limit.Unit)
+		if used+size > budget {
+			break
+		}
+		used += size
+		end++
+	}
+	for end > 0 && end < len(runes) && isContinuationRune(runes[end]) {
+		end--
+	}
+	for end > 0 && runes[end-1] == '\u200d' {
+		end--
+	}
+	return string(runes[:end]) + suffix
+}
+
+func isContinuationRune(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Variation_Selector) || r == '\u200d' || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
@@ -0,0 +1,94 @@
+package utils_test
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"testing"
+)
+
+func TestDescriptionLimiterFit(t *testing.T) {
+	limiter, err := utils.NewDescriptionLimiter("glue.column.comment=10 bigquery.column.description=20", "https://example.com/assets/{id}")
+	if err != nil {
+		t.Fatalf("NewDescriptionLimiter failed. %s", err.Error())
+	}
+	testCases := []struct {
+		name          string
+		field         string
+		desc          string
+		assetID       string
+		want          string
+		wantTruncated bool
+	}{
+		{
+			name:  "within limit",
+			field: utils.FieldGlueColumnComment,
+			desc:  "【QDIC】説明",
+			want:  "【QDIC】説明",
+		},
+		{
+			name:          "japanese characters",
+			field:         utils.FieldGlueColumnComment,
+			desc:          "【QDIC】顧客の注文明細です",
+			want:          "【QDIC】顧客の…",
+			wantTruncated: true,
+		},
+		{
+			name:          "emoji is counted as two utf16 code units",
+			field:         utils.FieldGlueColumnComment,
+			desc:          "【QDIC】😀😀😀",
+			want:          "【QDIC】😀…",
+			wantTruncated: true,
+		},
+		{
+			name:          "dakuten is not split from its base letter",
+			field:         utils.FieldGlueColumnComment,
+			desc:          "【QDIC】あいがく",
+			want:          "【QDIC】あい…",
+			wantTruncated: true,
+		},
+		{
+			name:          "link is appended when it fits",
+			field:         utils.FieldBigQueryDatasetDescription,
+			desc:          string(make([]rune, 20000)),
+			assetID:       "schm-1234",
+			want:          string(make([]rune, 16384-len([]rune("… https://example.com/assets/schm-1234")))) + "… https://example.com/assets/schm-1234",
+			wantTruncated: true,
+		},
+		{
+			name:          "link is dropped when it is too long for the field",
+			field:         utils.FieldBigQueryColumnDescription,
+			desc:          "abcdefghijklmnopqrstuvwxyz",
+			assetID:       "clmn-1234",
+			want:          "abcdefghijklmnopqrs…",
+			wantTruncated: true,
+		},
+		{
+			name:  "no limit",
+			field: utils.FieldDenodoVdpViewDescription,
+			desc:  "abcdefghijklmnopqrstuvwxyz",
+			want:  "abcdefghijklmnopqrstuvwxyz",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, truncated := limiter.Fit(tt.field, tt.desc, tt.assetID)
+			if res != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("want %s, %v but got %s, %v.", tt.want, tt.wantTruncated, res, truncated)
+			}
+		})
+	}
+}
+
+func TestNewDescriptionLimiterInvalidOverrides(t *testing.T) {
+	testCases := []string{
+		"glue.column.comment",
+		"glue.column.comment=abc",
+		"glue.column.comment=-1",
+		"unknown.field=10",
+	}
+	for _, testCase := range testCases {
+		_, err := utils.NewDescriptionLimiter(testCase, "")
+		if err == nil {
+			t.Errorf("expected an error for %s but got nil.", testCase)
+		}
+	}
+}
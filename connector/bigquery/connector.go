@@ -3,28 +3,64 @@ package bigquery
 import (
 	"fmt"
 	"os"
+	"quollio-reverse-agent/common/code"
 	"quollio-reverse-agent/common/logger"
+	"quollio-reverse-agent/common/report"
 	"quollio-reverse-agent/common/utils"
 	"quollio-reverse-agent/repository/bigquery"
 	"quollio-reverse-agent/repository/dataplex"
 	"quollio-reverse-agent/repository/qdc"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	bq "cloud.google.com/go/bigquery"
 	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
 )
 
 type BigQueryConnector struct {
-	QDCExternalAPIClient qdc.QDCExternalAPI
-	DataplexRepo         dataplex.DataplexClient
-	BigQueryRepo         bigquery.BigQueryClient
-	AssetCreatedBy       string
-	OverwriteMode        string
-	PrefixForUpdate      string
-	Logger               *logger.BuiltinLogger
+	QDCExternalAPIClient   qdc.QDCExternalAPI
+	DataplexRepo           dataplex.DataplexClient
+	BigQueryRepo           bigquery.BigQueryClient
+	AssetCreatedBy         string
+	AssetFilter            qdc.AssetFilter
+	AssetStatePolicy       utils.AssetStatePolicy
+	ContactDirectory       ContactDirectory
+	ContactSyncEnabled     bool
+	DeprecationLabelKey    string
+	DescriptionLimiter     utils.DescriptionLimiter
+	LabelMapping           LabelMapping
+	OverwriteMode          string
+	PrefixForUpdate        string
+	PolicyTagMapping       PolicyTagMapping
+	PolicyTagCreateMissing bool
+	PolicyTagKeepUnmanaged bool
+	ProjectFilter          ProjectFilter
+	TagDictionary          qdc.TagDictionary
+	TagSyncEnabled         bool
+	TagTemplateID          string
+	TagTemplateProject     string
+	Report                 *report.Report
+	Logger                 *logger.BuiltinLogger
+
+	ensuredTagTemplates map[string]bool
+}
+
+const defaultDeprecationLabelKey = "qdic_deprecated"
+
+// maxRetryAttempts is the number of the attempts of the calls that fail with the errors to be retried.
+const maxRetryAttempts = 3
+
+// retryBackoff is the wait before the first retry. It doubles on every retry.
+var retryBackoff = time.Second
+
+// labelUpdater is implemented by bq.DatasetMetadataToUpdate and bq.TableMetadataToUpdate.
+type labelUpdater interface {
+	SetLabel(name, value string)
+	DeleteLabel(name string)
 }
 
-func NewBigqueryConnector(prefixForUpdate, overwriteMode string, logger *logger.BuiltinLogger) (BigQueryConnector, error) {
+func NewBigqueryConnector(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, logger *logger.BuiltinLogger) (BigQueryConnector, error) {
 	serviceCreds := os.Getenv("GOOGLE_CLOUD_SERVICE_ACCOUNT_CREDENTIALS")
 	dataplexClient, err := dataplex.NewDataplexClient(serviceCreds)
 	if err != nil {
@@ -39,18 +75,67 @@ func NewBigqueryConnector(prefixForUpdate, overwriteMode string, logger *logger.
 	qdcClientID := os.Getenv("QDC_CLIENT_ID")
 	qdcClientSecret := os.Getenv("QDC_CLIENT_SECRET")
 	assetCreatedBy := os.Getenv("QDC_ASSET_CREATED_BY")
+	deprecationLabelKey := os.Getenv("BIGQUERY_DEPRECATION_LABEL_KEY")
+	if deprecationLabelKey == "" {
+		deprecationLabelKey = defaultDeprecationLabelKey
+	}
+	assetFilter, err := qdc.NewAssetFilter(os.Getenv("QDC_ASSET_FILTER_INCLUDE"), os.Getenv("QDC_ASSET_FILTER_EXCLUDE"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to initialize AssetFilter in BigQuery Connector %s", err)
+	}
+	descriptionLimiter, err := utils.NewDescriptionLimiter(os.Getenv("DESCRIPTION_LENGTH_LIMITS"), os.Getenv("QDC_ASSET_URL_TEMPLATE"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to initialize DescriptionLimiter in BigQuery Connector %s", err)
+	}
+	policyTagMapping, err := LoadPolicyTagMapping(os.Getenv("BIGQUERY_POLICY_TAG_MAPPING_FILE"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to load PolicyTagMapping in BigQuery Connector %s", err)
+	}
+	labelMapping, err := LoadLabelMapping(os.Getenv("BIGQUERY_LABEL_MAPPING_FILE"), os.Getenv("BIGQUERY_LABEL_KEY_PREFIX"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to load LabelMapping in BigQuery Connector %s", err)
+	}
+	contactDirectory, err := LoadContactDirectory(os.Getenv("DATAPLEX_CONTACT_DIRECTORY_FILE"), os.Getenv("DATAPLEX_CONTACT_DESIGNATION"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to load ContactDirectory in BigQuery Connector %s", err)
+	}
+	projectFilter, err := NewProjectFilter(os.Getenv("BIGQUERY_PROJECT_ALLOW_LIST"), os.Getenv("BIGQUERY_PROJECT_DENY_LIST"))
+	if err != nil {
+		return BigQueryConnector{}, fmt.Errorf("Failed to initialize ProjectFilter in BigQuery Connector %s", err)
+	}
+	policyTagCreateMissing := os.Getenv("BIGQUERY_POLICY_TAG_CREATE_MISSING") == "true"
+	policyTagKeepUnmanaged := os.Getenv("BIGQUERY_POLICY_TAG_KEEP_UNMANAGED") != "false"
+	tagTemplateID := os.Getenv("DATAPLEX_TAG_TEMPLATE_ID")
+	if tagTemplateID == "" {
+		tagTemplateID = defaultTagTemplateID
+	}
 	externalAPI, err := qdc.NewQDCExternalAPI(qdcBaseURL, qdcClientID, qdcClientSecret, logger)
 	if err != nil {
 		return BigQueryConnector{}, fmt.Errorf("Failed to initialize QDCExternalAPI client in BigQuery Connector %s", err)
 	}
 	connector := BigQueryConnector{
-		QDCExternalAPIClient: externalAPI,
-		DataplexRepo:         dataplexClient,
-		BigQueryRepo:         bigqueryClient,
-		AssetCreatedBy:       assetCreatedBy,
-		OverwriteMode:        overwriteMode,
-		PrefixForUpdate:      prefixForUpdate,
-		Logger:               logger,
+		QDCExternalAPIClient:   externalAPI,
+		DataplexRepo:           dataplexClient,
+		BigQueryRepo:           bigqueryClient,
+		AssetCreatedBy:         assetCreatedBy,
+		AssetFilter:            assetFilter,
+		AssetStatePolicy:       assetStatePolicy,
+		ContactDirectory:       contactDirectory,
+		ContactSyncEnabled:     os.Getenv("DATAPLEX_CONTACT_SYNC") == "true",
+		DeprecationLabelKey:    deprecationLabelKey,
+		DescriptionLimiter:     descriptionLimiter,
+		LabelMapping:           labelMapping,
+		OverwriteMode:          overwriteMode,
+		PrefixForUpdate:        prefixForUpdate,
+		PolicyTagMapping:       policyTagMapping,
+		PolicyTagCreateMissing: policyTagCreateMissing,
+		PolicyTagKeepUnmanaged: policyTagKeepUnmanaged,
+		ProjectFilter:          projectFilter,
+		TagSyncEnabled:         os.Getenv("DATAPLEX_TAG_SYNC") == "true",
+		TagTemplateID:          tagTemplateID,
+		TagTemplateProject:     os.Getenv("DATAPLEX_TAG_TEMPLATE_PROJECT"),
+		Report:                 report.NewReport(),
+		Logger:                 logger,
 	}
 
 	return connector, nil
@@ -58,21 +143,59 @@ func NewBigqueryConnector(prefixForUpdate, overwriteMode string, logger *logger.
 
 func (b *BigQueryConnector) ReflectDatasetDescToBigQuery(schemaAssets []qdc.Data) error {
 	for _, schemaAsset := range schemaAssets {
-		if schemaAsset.IsLost {
-			b.Logger.Debug("Skip schema update because it is lost in qdc : %s", schemaAsset.PhysicalName)
+		action := b.AssetStatePolicy.GetAction(schemaAsset.IsLost, schemaAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			b.Logger.Debug("Skip schema update because it is lost or archived in qdc : %s", schemaAsset.PhysicalName)
 			continue
 		}
-		datasetMetadata, err := b.BigQueryRepo.GetDatasetMetadata(schemaAsset.PhysicalName)
-		if err != nil {
-			b.Logger.Error("Failed to GetDatasetMetadata. : %s", schemaAsset.PhysicalName)
+		// MEMO: The datasets are addressed by the project in the path, since the project of the client can differ from it.
+		projectAsset := qdc.GetSpecifiedAssetFromPath(schemaAsset, "schema4")
+		datasetName := fmt.Sprintf("%s.%s", projectAsset.Name, schemaAsset.PhysicalName)
+		var datasetMetadata *bq.DatasetMetadata
+		err := code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+			var err error
+			datasetMetadata, err = b.BigQueryRepo.GetDatasetMetadata(projectAsset.Name, schemaAsset.PhysicalName)
 			return err
+		})
+		if err != nil {
+			if err := b.handleAssetError(datasetName, "dataset", err); err != nil {
+				b.Logger.Error("Failed to GetDatasetMetadata. : %s", schemaAsset.PhysicalName)
+				return err
+			}
+			continue
 		}
-		if shouldUpdateBqDataset(b.PrefixForUpdate, b.OverwriteMode, datasetMetadata, schemaAsset) {
-			descWithPrefix := utils.AddPrefixToStringIfNotHas(b.PrefixForUpdate, schemaAsset.Description)
-			_, err = b.BigQueryRepo.UpdateDatasetDescription(schemaAsset.PhysicalName, descWithPrefix)
-			if err != nil {
-				b.Logger.Error("The update was failed.: %s", schemaAsset.PhysicalName)
+		var metadataToUpdate bq.DatasetMetadataToUpdate
+		datasetShouldBeUpdated := false
+		switch action {
+		case utils.AssetStateUpdate:
+			if shouldUpdateBqDataset(b.PrefixForUpdate, b.OverwriteMode, datasetMetadata, schemaAsset) {
+				descWithPrefix := utils.RenderDescription(b.PrefixForUpdate, schemaAsset.Description, utils.FormatPlainText)
+				metadataToUpdate.Description = b.fitDescription(utils.FieldBigQueryDatasetDescription, schemaAsset.PhysicalName, schemaAsset.ID, descWithPrefix)
+				datasetShouldBeUpdated = true
+			}
+		default:
+			if desc, ok := b.AssetStatePolicy.GenDescription(action, b.PrefixForUpdate, b.OverwriteMode, utils.FormatPlainText, datasetMetadata.Description, schemaAsset.Description); ok {
+				metadataToUpdate.Description = b.fitDescription(utils.FieldBigQueryDatasetDescription, schemaAsset.PhysicalName, schemaAsset.ID, desc)
+				datasetShouldBeUpdated = true
+			}
+		}
+		if updateDeprecationLabel(&metadataToUpdate, datasetMetadata.Labels, b.DeprecationLabelKey, action == utils.AssetStateDeprecate) {
+			datasetShouldBeUpdated = true
+		}
+		if b.updateTagLabels(&metadataToUpdate, datasetMetadata.Labels, action, schemaAsset) {
+			datasetShouldBeUpdated = true
+		}
+		if datasetShouldBeUpdated {
+			err = code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+				_, err := b.BigQueryRepo.UpdateDatasetMetadata(projectAsset.Name, schemaAsset.PhysicalName, metadataToUpdate)
 				return err
+			})
+			if err != nil {
+				if err := b.handleAssetError(datasetName, "dataset", err); err != nil {
+					b.Logger.Error("The update was failed.: %s", schemaAsset.PhysicalName)
+					return err
+				}
+				continue
 			}
 			b.Logger.Debug("The description of the asset was updated.: %s", schemaAsset.PhysicalName)
 		}
@@ -85,16 +208,26 @@ func (b *BigQueryConnector) ReflectTableAttributeToBigQuery(tableAssets []qdc.Da
 		projectAsset := qdc.GetSpecifiedAssetFromPath(tableAsset, "schema4")
 		datasetAsset := qdc.GetSpecifiedAssetFromPath(tableAsset, "schema3")
 
-		if tableAsset.IsLost {
-			b.Logger.Debug("Skip table update because it is lost in qdc : %s->%s->%s ", projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
+		action := b.AssetStatePolicy.GetAction(tableAsset.IsLost, tableAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			b.Logger.Debug("Skip table update because it is lost or archived in qdc : %s->%s->%s ", projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
 		var metadataToUpdate bq.TableMetadataToUpdate
 
-		tableMetadata, err := b.BigQueryRepo.GetTableMetadata(datasetAsset.Name, tableAsset.PhysicalName)
-		if err != nil {
-			b.Logger.Error("Failed to GetTableMetadata: %s", tableMetadata.Name)
+		bqTableName := fmt.Sprintf("%s.%s.%s", projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
+		var tableMetadata *bq.TableMetadata
+		err := code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+			var err error
+			tableMetadata, err = b.BigQueryRepo.GetTableMetadata(projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
 			return err
+		})
+		if err != nil {
+			if err := b.handleAssetError(bqTableName, "table", err); err != nil {
+				b.Logger.Error("Failed to GetTableMetadata: %s", tableAsset.PhysicalName)
+				return err
+			}
+			continue
 		}
 
 		columnAssets, err := b.QDCExternalAPIClient.GetChildAssetsByParentAsset(tableAsset)
@@ -103,36 +236,99 @@ func (b *BigQueryConnector) ReflectTableAttributeToBigQuery(tableAssets []qdc.Da
 			return err
 		}
 
-		tableSchemas, shouldSchemaUpdated := GetDescUpdatedSchema(b.PrefixForUpdate, b.OverwriteMode, columnAssets, tableMetadata)
+		// MEMO: The include conditions that matched the table aren't applied to its columns again.
+		columnAssets = b.AssetFilter.FilterAssetsInScope(columnAssets)
+		tableSchemas, shouldSchemaUpdated := GetDescUpdatedSchema(b.PrefixForUpdate, b.OverwriteMode, b.AssetStatePolicy, columnAssets, tableMetadata)
+		if GetPolicyTagUpdatedSchema(b.PolicyTagMapping, b.PolicyTagKeepUnmanaged, b.AssetStatePolicy, columnAssets, tableSchemas) {
+			b.Logger.Debug("The policy tags of table asset will be updated.: %s", tableAsset.PhysicalName)
+			shouldSchemaUpdated = true
+		}
 		if shouldSchemaUpdated {
-			metadataToUpdate.Schema = tableSchemas
+			metadataToUpdate.Schema = b.fitColumnDescriptions(bqTableName, tableSchemas, columnAssets)
+		}
+		shouldLabelUpdated := updateDeprecationLabel(&metadataToUpdate, tableMetadata.Labels, b.DeprecationLabelKey, action == utils.AssetStateDeprecate)
+		if b.updateTagLabels(&metadataToUpdate, tableMetadata.Labels, action, tableAsset) {
+			shouldLabelUpdated = true
+		}
+		if shouldSchemaUpdated || shouldLabelUpdated {
 			// Update table and schema description
-			_, err = b.BigQueryRepo.UpdateTableMetadata(datasetAsset.Name, tableAsset.PhysicalName, metadataToUpdate)
-			if err != nil {
-				b.Logger.Error("Failed to UpdateTableMetadata: %s", tableAsset.PhysicalName)
+			err = code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+				_, err := b.BigQueryRepo.UpdateTableMetadata(projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName, metadataToUpdate)
 				return err
+			})
+			if err != nil {
+				if err := b.handleAssetError(bqTableName, "table", err); err != nil {
+					b.Logger.Error("Failed to UpdateTableMetadata: %s", tableAsset.PhysicalName)
+					return err
+				}
+				continue
 			}
 			b.Logger.Debug("The schema fields of table asset was updated.: %s", tableAsset.PhysicalName)
 		}
 
 		// Update table overview
 		bqTableFQN := fmt.Sprintf("bigquery:%s.%s.%s", projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
-		if !qdc.IsAssetContainsValueAsDescription(tableAsset) {
+		shouldOverviewChecked := action != utils.AssetStateUpdate || qdc.IsAssetContainsValueAsDescription(tableAsset)
+		if !shouldOverviewChecked && !b.TagSyncEnabled && !b.ContactSyncEnabled {
 			b.Logger.Debug("Skip LookupEntry and Update View because the description of qdc table asset is empty. Project: %s, Dataset: %s, Table: %s ", projectAsset.Name, datasetAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
-		tableAssetEntry, err := b.DataplexRepo.LookupEntry(bqTableFQN, projectAsset.Name, tableMetadata.Location)
-		if err != nil {
-			b.Logger.Error("Failed to LookupEntry.: %s", tableAsset.PhysicalName)
+		var tableAssetEntry *datacatalogpb.Entry
+		err = code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+			var err error
+			tableAssetEntry, err = b.DataplexRepo.LookupEntry(bqTableFQN, projectAsset.Name, tableMetadata.Location)
 			return err
+		})
+		if err != nil {
+			if err := b.handleAssetError(bqTableName, "entry", err); err != nil {
+				b.Logger.Error("Failed to LookupEntry.: %s", tableAsset.PhysicalName)
+				return err
+			}
+			continue
 		}
-		if shouldUpdateBqTable(b.PrefixForUpdate, b.OverwriteMode, tableAssetEntry, tableAsset) {
-			b.Logger.Debug("The overview of table asset will be updated.: %s", tableAsset.PhysicalName)
-			descWithPrefix := utils.AddPrefixToStringIfNotHas(b.PrefixForUpdate, tableAsset.Description)
-			_, err := b.DataplexRepo.ModifyEntryOverview(tableAssetEntry.Name, descWithPrefix)
+		if b.TagSyncEnabled {
+			tagTemplateProject := b.TagTemplateProject
+			if tagTemplateProject == "" {
+				tagTemplateProject = projectAsset.Name
+			}
+			// MEMO: The tags are independent of the contacts and the overview, so those are updated even if the tags are skipped.
+			tagTemplateName, err := b.ensureTagTemplate(tagTemplateProject, tableMetadata.Location)
+			if err == nil {
+				err = b.syncEntryTags(tableAssetEntry, tagTemplateName, bqTableName, tableAsset, columnAssets, tableMetadata.Schema)
+			}
+			if err != nil {
+				if err := b.handleAssetError(bqTableName, "tag", err); err != nil {
+					b.Logger.Error("Failed to sync the tags.: %s", tableAsset.PhysicalName)
+					return err
+				}
+			}
+		}
+		if b.ContactSyncEnabled {
+			err = b.syncEntryContacts(tableAssetEntry, bqTableFQN, tableAsset)
 			if err != nil {
-				b.Logger.Error("The update for the overview of the table asset was failed.: %s", tableAsset.PhysicalName)
+				if err := b.handleAssetError(bqTableName, "contacts", err); err != nil {
+					b.Logger.Error("Failed to syncEntryContacts.: %s", tableAsset.PhysicalName)
+					return err
+				}
+			}
+		}
+		if !shouldOverviewChecked {
+			continue
+		}
+		overviewForUpdate, shouldOverviewUpdated := genOverviewForUpdate(b.PrefixForUpdate, b.OverwriteMode, b.AssetStatePolicy, action, tableAssetEntry, tableAsset)
+		if shouldOverviewUpdated {
+			b.Logger.Debug("The overview of table asset will be updated.: %s", tableAsset.PhysicalName)
+			overviewForUpdate = b.fitDescription(utils.FieldDataplexTableOverview, bqTableFQN, tableAsset.ID, overviewForUpdate)
+			err := code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+				_, err := b.DataplexRepo.ModifyEntryOverview(tableAssetEntry.Name, overviewForUpdate)
 				return err
+			})
+			if err != nil {
+				if err := b.handleAssetError(bqTableName, "overview", err); err != nil {
+					b.Logger.Error("The update for the overview of the table asset was failed.: %s", tableAsset.PhysicalName)
+					return err
+				}
+				continue
 			}
 			b.Logger.Debug("The update for the overview of the table asset was succeeded.: %s", tableAsset.PhysicalName)
 		}
@@ -141,36 +337,64 @@ func (b *BigQueryConnector) ReflectTableAttributeToBigQuery(tableAssets []qdc.Da
 }
 
 func (b *BigQueryConnector) ReflectMetadataToDataCatalog() error {
+	defer b.Report.Print(b.Logger)
+	if !b.PolicyTagMapping.IsEmpty() {
+		b.Logger.Info("Resolve policy tags in the mapping")
+		err := b.resolvePolicyTags()
+		if err != nil {
+			b.Logger.Error("Failed to resolvePolicyTags: %s", err.Error())
+			return err
+		}
+	}
+	tagSelectors := append(b.LabelMapping.TagSelectors(), b.PolicyTagMapping.TagSelectors()...)
+	if b.TagSyncEnabled || qdc.HasTagNames(tagSelectors) {
+		b.Logger.Info("Get QDIC tag dictionary")
+		tagDictionary, err := b.QDCExternalAPIClient.GetTagDictionary()
+		if err != nil {
+			b.Logger.Error("Failed to GetTagDictionary: %s", err.Error())
+			return err
+		}
+		b.TagDictionary = tagDictionary
+		if err := qdc.ResolveTagNames(tagDictionary, tagSelectors); err != nil {
+			b.Logger.Error("Failed to resolve the tag names in the mappings: %s", err.Error())
+			return err
+		}
+	}
 	b.Logger.Info("List BigQuery project assets")
 	rootAssets, err := b.QDCExternalAPIClient.GetAllRootAssets("bigquery", b.AssetCreatedBy)
 	if err != nil {
 		b.Logger.Error("Failed to GetAllBigQueryRootAssets: %s", err.Error())
 		return err
 	}
+	rootAssets, skippedProjects := b.ProjectFilter.FilterProjectAssets(rootAssets)
+	if len(skippedProjects) > 0 {
+		b.Logger.Info("Skip the projects excluded by the project allow and deny lists: %s", strings.Join(skippedProjects, ", "))
+	}
 
 	b.Logger.Info("List BigQuery schema assets")
-	schemaAssets, err := b.QDCExternalAPIClient.GetAllChildAssetsByID(rootAssets)
+	schemaAssets, err := b.QDCExternalAPIClient.GetAllChildAssetsByID(b.AssetFilter.FilterAssetsInScope(rootAssets))
 	if err != nil {
 		b.Logger.Error("Failed to GetAllChildAssetsByID for schemaAssets: %s", err.Error())
 		return err
 	}
 
 	b.Logger.Info("Start to run ReflectDatasetDescToBigQuery")
-	err = b.ReflectDatasetDescToBigQuery(schemaAssets)
+	err = b.ReflectDatasetDescToBigQuery(b.AssetFilter.FilterAssets(schemaAssets))
 	if err != nil {
 		b.Logger.Error("Failed to ReflectDatasetDescToBigQuery for schemaAssets: %s", err.Error())
 		return err
 	}
 
 	b.Logger.Info("List BigQuery table assets")
-	tableAssets, err := b.QDCExternalAPIClient.GetAllChildAssetsByID(schemaAssets)
+	// MEMO: The tables of the datasets out of the scope of AssetFilter aren't read.
+	tableAssets, err := b.QDCExternalAPIClient.GetAllChildAssetsByID(b.AssetFilter.FilterAssetsInScope(schemaAssets))
 	if err != nil {
 		b.Logger.Error("Failed to GetAllChildAssetsByID: %s", err.Error())
 		return err
 	}
 
 	b.Logger.Info("Start to run ReflectTableAttributeToBigQuery")
-	err = b.ReflectTableAttributeToBigQuery(tableAssets)
+	err = b.ReflectTableAttributeToBigQuery(b.AssetFilter.FilterAssets(tableAssets))
 	if err != nil {
 		b.Logger.Error("Failed to ReflectTableAttributeToBigQuery: %s", err.Error())
 		return err
@@ -178,6 +402,33 @@ func (b *BigQueryConnector) ReflectMetadataToDataCatalog() error {
 	return nil
 }
 
+// handleAssetError reports the error and returns nil if the asset can be skipped. Otherwise, it returns the error to abort the run.
+func (b *BigQueryConnector) handleAssetError(target, field string, err error) error {
+	if code.Decide(err) != code.Skip {
+		return err
+	}
+	b.Report.Add(report.WARNING, "bigquery", target, field, fmt.Sprintf("Skipped the update. %s", err.Error()))
+	return nil
+}
+
+// fitDescription truncates the value to the limit of the BigQuery or Dataplex field and reports the truncation.
+func (b *BigQueryConnector) fitDescription(field, target, assetID, desc string) string {
+	fitted, truncated := b.DescriptionLimiter.Fit(field, desc, assetID)
+	if truncated {
+		b.Report.Add(report.WARNING, "bigquery", target, field, fmt.Sprintf("Truncated from %d to %d characters.", utf8.RuneCountInString(desc), utf8.RuneCountInString(fitted)))
+	}
+	return fitted
+}
+
+func (b *BigQueryConnector) fitColumnDescriptions(tableFQN string, tableSchemas []*bq.FieldSchema, columnAssets []qdc.Data) []*bq.FieldSchema {
+	mapColumnAssetByColumnName := MapColumnAssetByColumnName(columnAssets)
+	for _, schemaField := range tableSchemas {
+		columnAsset := mapColumnAssetByColumnName[schemaField.Name]
+		schemaField.Description = b.fitDescription(utils.FieldBigQueryColumnDescription, fmt.Sprintf("%s.%s", tableFQN, schemaField.Name), columnAsset.ID, schemaField.Description)
+	}
+	return tableSchemas
+}
+
 func MapColumnAssetByColumnName(columnAssets []qdc.Data) map[string]qdc.Data {
 	mapColumnAssetsByColumnName := make(map[string]qdc.Data)
 	for _, columnAsset := range columnAssets {
@@ -186,17 +437,26 @@ func MapColumnAssetByColumnName(columnAssets []qdc.Data) map[string]qdc.Data {
 	return mapColumnAssetsByColumnName
 }
 
-func GetDescUpdatedSchema(prefixForUpdate, overwriteMode string, columnAssets []qdc.Data, tableMetadata *bq.TableMetadata) ([]*bq.FieldSchema, bool) {
+func GetDescUpdatedSchema(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, columnAssets []qdc.Data, tableMetadata *bq.TableMetadata) ([]*bq.FieldSchema, bool) {
 	var tableSchemas []*bq.FieldSchema
 	shouldSchemaUpdated := false
 	mapColumnAssetByColumnName := MapColumnAssetByColumnName(columnAssets)
 	for _, schemaField := range tableMetadata.Schema {
 		newSchemaField := schemaField // copy
 		if columnAsset, ok := mapColumnAssetByColumnName[newSchemaField.Name]; ok {
-			if shouldUpdateBqColumn(prefixForUpdate, overwriteMode, newSchemaField, columnAsset) {
-				descWithPrefix := utils.AddPrefixToStringIfNotHas(prefixForUpdate, columnAsset.Description)
-				newSchemaField.Description = descWithPrefix
-				shouldSchemaUpdated = true
+			action := assetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+			switch action {
+			case utils.AssetStateUpdate:
+				if shouldUpdateBqColumn(prefixForUpdate, overwriteMode, newSchemaField, columnAsset) {
+					descWithPrefix := utils.RenderDescription(prefixForUpdate, columnAsset.Description, utils.FormatPlainText)
+					newSchemaField.Description = descWithPrefix
+					shouldSchemaUpdated = true
+				}
+			default:
+				if desc, ok := assetStatePolicy.GenDescription(action, prefixForUpdate, overwriteMode, utils.FormatPlainText, newSchemaField.Description, columnAsset.Description); ok {
+					newSchemaField.Description = desc
+					shouldSchemaUpdated = true
+				}
 			}
 		}
 		tableSchemas = append(tableSchemas, newSchemaField)
@@ -204,6 +464,35 @@ func GetDescUpdatedSchema(prefixForUpdate, overwriteMode string, columnAssets []
 	return tableSchemas, shouldSchemaUpdated
 }
 
+// genOverviewForUpdate returns the Dataplex overview that should be written for the table asset.
+func genOverviewForUpdate(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, action string, tableEntry *datacatalogpb.Entry, qdcTable qdc.Data) (string, bool) {
+	if action == utils.AssetStateUpdate {
+		if shouldUpdateBqTable(prefixForUpdate, overwriteMode, tableEntry, qdcTable) {
+			return utils.RenderDescription(prefixForUpdate, qdcTable.Description, utils.FormatHTML), true
+		}
+		return "", false
+	}
+	var currentOverview string
+	if tableEntry.BusinessContext != nil && tableEntry.BusinessContext.EntryOverview != nil {
+		currentOverview = tableEntry.BusinessContext.EntryOverview.Overview
+	}
+	return assetStatePolicy.GenDescription(action, prefixForUpdate, overwriteMode, utils.FormatHTML, currentOverview, qdcTable.Description)
+}
+
+// updateDeprecationLabel sets or deletes the deprecation label. It returns true when the labels have to be updated.
+func updateDeprecationLabel(updater labelUpdater, currentLabels map[string]string, key string, isDeprecated bool) bool {
+	_, hasLabel := currentLabels[key]
+	if key == "" || isDeprecated == hasLabel {
+		return false
+	}
+	if isDeprecated {
+		updater.SetLabel(key, "true")
+	} else {
+		updater.DeleteLabel(key)
+	}
+	return true
+}
+
 func shouldUpdateBqDataset(prefixForUpdate, overwriteMode string, datasetMetadata *bq.DatasetMetadata, qdcDataset qdc.Data) bool {
 	if overwriteMode == utils.OverwriteAll && qdcDataset.Description != "" {
 		return true
@@ -225,8 +514,8 @@ func shouldUpdateBqTable(prefixForUpdate, overwriteMode string, tableMetadata *d
 		return true
 	}
 
-	// MEMO: BusinessContext is markdown. Then, it's possible that `<p>` is unexpectedly inserted into the description.
-	if (tableMetadata.BusinessContext == nil || strings.HasPrefix(strings.Replace(tableMetadata.BusinessContext.EntryOverview.Overview, "<p>", "", -1), prefixForUpdate)) && qdcTable.Description != "" {
+	// MEMO: BusinessContext is HTML. The prefix is checked on the visible text.
+	if (tableMetadata.BusinessContext == nil || utils.HasPrefixInFormat(tableMetadata.BusinessContext.EntryOverview.Overview, prefixForUpdate, utils.FormatHTML)) && qdcTable.Description != "" {
 		return true
 	}
 	return false
@@ -478,7 +478,7 @@ func TestGetDescUpdatedSchema(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		res, b := bigquery.GetDescUpdatedSchema("【QDIC】", utils.OverwriteIfEmpty, testCase.Input.GetAssetByIDsResponseData, testCase.Input.TableMetadata)
+		res, b := bigquery.GetDescUpdatedSchema("【QDIC】", utils.OverwriteIfEmpty, utils.DefaultAssetStatePolicy(), testCase.Input.GetAssetByIDsResponseData, testCase.Input.TableMetadata)
 		if !reflect.DeepEqual(res, testCase.Expect.FieldSchema) || b != testCase.Expect.ShouldBeUpdated {
 			t.Errorf("want %+v, but got %+v", testCase.Expect, res)
 		}
@@ -0,0 +1,118 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+
+	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
+)
+
+const defaultContactDesignation = "Data Steward"
+
+// ContactDirectory maps QDIC user names to the emails of the people.
+type ContactDirectory struct {
+	Designation string            `json:"-"`
+	Users       map[string]string `json:"users"`
+}
+
+func LoadContactDirectory(path, designation string) (ContactDirectory, error) {
+	if designation == "" {
+		designation = defaultContactDesignation
+	}
+	directory := ContactDirectory{Designation: designation, Users: map[string]string{}}
+	if path == "" {
+		return directory, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ContactDirectory{}, err
+	}
+	if err := json.Unmarshal(content, &directory); err != nil {
+		return ContactDirectory{}, fmt.Errorf("invalid contact directory %s: %s", path, err)
+	}
+	for userName, email := range directory.Users {
+		if !strings.Contains(email, "@") {
+			return ContactDirectory{}, fmt.Errorf("user %s has no valid email", userName)
+		}
+	}
+	return directory, nil
+}
+
+// GetEmails returns the emails of the creator and the updaters of the asset without duplication.
+// The user names that are not in the directory are returned as unknown.
+func (d ContactDirectory) GetEmails(asset qdc.Data) (emails []string, unknown []string) {
+	seen := make(map[string]bool)
+	for _, userName := range append([]string{asset.CreatedBy}, asset.UpdatedBy...) {
+		if userName == "" || seen[userName] {
+			continue
+		}
+		seen[userName] = true
+		email, ok := d.Users[userName]
+		if !ok {
+			unknown = append(unknown, userName)
+			continue
+		}
+		emails = append(emails, email)
+	}
+	return emails, unknown
+}
+
+// GenContactPeople returns the people of the entry with the given emails added.
+// The existing people are kept as they are. It returns false when no one has to be added.
+func GenContactPeople(current []*datacatalogpb.Contacts_Person, emails []string, designation string) ([]*datacatalogpb.Contacts_Person, bool) {
+	existing := make(map[string]bool)
+	for _, person := range current {
+		existing[normalizeContactEmail(person.Email)] = true
+	}
+	people := append([]*datacatalogpb.Contacts_Person{}, current...)
+	shouldBeUpdated := false
+	for _, email := range emails {
+		if existing[normalizeContactEmail(email)] {
+			continue
+		}
+		existing[normalizeContactEmail(email)] = true
+		people = append(people, &datacatalogpb.Contacts_Person{
+			Designation: designation,
+			Email:       email,
+		})
+		shouldBeUpdated = true
+	}
+	return people, shouldBeUpdated
+}
+
+// normalizeContactEmail extracts the address from the formats such as `John Doe<john.doe@xyz>`.
+func normalizeContactEmail(email string) string {
+	if start := strings.LastIndex(email, "<"); start >= 0 {
+		if end := strings.LastIndex(email, ">"); end > start {
+			email = email[start+1 : end]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// syncEntryContacts adds the creator and the updaters of the table asset to the contacts of the entry.
+// Assets with SKIP or CLEAR action are left as they are.
+func (b *BigQueryConnector) syncEntryContacts(entry *datacatalogpb.Entry, tableFQN string, tableAsset qdc.Data) error {
+	action := b.AssetStatePolicy.GetAction(tableAsset.IsLost, tableAsset.IsArchived)
+	if action == utils.AssetStateSkip || action == utils.AssetStateClear {
+		return nil
+	}
+	emails, unknown := b.ContactDirectory.GetEmails(tableAsset)
+	for _, userName := range unknown {
+		b.Report.Add(report.WARNING, "dataplex", tableFQN, "contacts", fmt.Sprintf("User %s is not in the contact directory.", userName))
+	}
+	people, shouldBeUpdated := GenContactPeople(entry.GetBusinessContext().GetContacts().GetPeople(), emails, b.ContactDirectory.Designation)
+	if !shouldBeUpdated {
+		return nil
+	}
+	if _, err := b.DataplexRepo.ModifyEntryContacts(entry.Name, people); err != nil {
+		return err
+	}
+	b.Logger.Debug("Updated contacts. entry: %s", entry.Name)
+	return nil
+}
@@ -0,0 +1,62 @@
+package bigquery
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContactDirectoryGetEmails(t *testing.T) {
+	directory := ContactDirectory{
+		Designation: "Data Steward",
+		Users: map[string]string{
+			"taro":   "taro@example.com",
+			"hanako": "hanako@example.com",
+		},
+	}
+	asset := qdc.Data{CreatedBy: "taro", UpdatedBy: []string{"hanako", "taro", "unknown"}}
+	emails, unknown := directory.GetEmails(asset)
+	if diff := cmp.Diff([]string{"taro@example.com", "hanako@example.com"}, emails); diff != "" {
+		t.Errorf("emails mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"unknown"}, unknown); diff != "" {
+		t.Errorf("unknown mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenContactPeople(t *testing.T) {
+	current := []*datacatalogpb.Contacts_Person{
+		{Designation: "Owner", Email: "Taro Yamada<Taro@example.com>"},
+	}
+	testCases := []struct {
+		name       string
+		emails     []string
+		wantEmails []string
+		wantUpdate bool
+	}{
+		{name: "existing person", emails: []string{"taro@example.com"}, wantEmails: []string{"Taro Yamada<Taro@example.com>"}, wantUpdate: false},
+		{name: "new person", emails: []string{"taro@example.com", "hanako@example.com", "hanako@example.com"}, wantEmails: []string{"Taro Yamada<Taro@example.com>", "hanako@example.com"}, wantUpdate: true},
+		{name: "no emails", emails: nil, wantEmails: []string{"Taro Yamada<Taro@example.com>"}, wantUpdate: false},
+	}
+	for _, tt := range testCases {
+		people, shouldBeUpdated := GenContactPeople(current, tt.emails, "Data Steward")
+		if shouldBeUpdated != tt.wantUpdate {
+			t.Errorf("%s: want %v but got %v", tt.name, tt.wantUpdate, shouldBeUpdated)
+		}
+		var emails []string
+		for _, person := range people {
+			emails = append(emails, person.Email)
+		}
+		if diff := cmp.Diff(tt.wantEmails, emails); diff != "" {
+			t.Errorf("%s: people mismatch (-want +got):\n%s", tt.name, diff)
+		}
+		if len(people) > 1 && people[1].Designation != "Data Steward" {
+			t.Errorf("%s: want Data Steward but got %s", tt.name, people[1].Designation)
+		}
+	}
+	if current[0].Designation != "Owner" || len(current) != 1 {
+		t.Errorf("the current people must not be changed")
+	}
+}
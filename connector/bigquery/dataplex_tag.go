@@ -0,0 +1,252 @@
+package bigquery
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+
+	bq "cloud.google.com/go/bigquery"
+	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultTagTemplateID = "quollio"
+
+const (
+	TagFieldLogicalName   = "logical_name"
+	TagFieldAssetID       = "asset_id"
+	TagFieldTags          = "tags"
+	TagFieldLastUpdatedBy = "last_updated_by"
+	TagFieldLastUpdatedAt = "last_updated_at"
+	TagFieldDeepLink      = "deep_link"
+)
+
+type tagTemplateFieldDef struct {
+	ID          string
+	DisplayName string
+	Type        datacatalogpb.FieldType_PrimitiveType
+}
+
+// tagTemplateFields is the definition of the managed tag template. Fields are created in this order.
+var tagTemplateFields = []tagTemplateFieldDef{
+	{ID: TagFieldLogicalName, DisplayName: "Logical name", Type: datacatalogpb.FieldType_STRING},
+	{ID: TagFieldAssetID, DisplayName: "QDIC asset ID", Type: datacatalogpb.FieldType_STRING},
+	{ID: TagFieldTags, DisplayName: "Tags", Type: datacatalogpb.FieldType_STRING},
+	{ID: TagFieldLastUpdatedBy, DisplayName: "Last updated by", Type: datacatalogpb.FieldType_STRING},
+	{ID: TagFieldLastUpdatedAt, DisplayName: "Last updated at", Type: datacatalogpb.FieldType_TIMESTAMP},
+	{ID: TagFieldDeepLink, DisplayName: "QDIC link", Type: datacatalogpb.FieldType_STRING},
+}
+
+func genTagTemplateField(def tagTemplateFieldDef, order int32) *datacatalogpb.TagTemplateField {
+	return &datacatalogpb.TagTemplateField{
+		DisplayName: def.DisplayName,
+		Type: &datacatalogpb.FieldType{
+			TypeDecl: &datacatalogpb.FieldType_PrimitiveType_{PrimitiveType: def.Type},
+		},
+		Order: order,
+	}
+}
+
+// ensureTagTemplate creates the tag template in the project and location, or adds the missing fields to it.
+// The name of the template is cached, so the template is checked only once in a run.
+func (b *BigQueryConnector) ensureTagTemplate(project, location string) (string, error) {
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, strings.ToLower(location))
+	tagTemplateName := fmt.Sprintf("%s/tagTemplates/%s", parent, b.TagTemplateID)
+	if b.ensuredTagTemplates[tagTemplateName] {
+		return tagTemplateName, nil
+	}
+	tagTemplate, err := b.DataplexRepo.GetTagTemplate(tagTemplateName)
+	if err != nil {
+		return "", err
+	}
+	if tagTemplate == nil {
+		fields := make(map[string]*datacatalogpb.TagTemplateField)
+		for i, def := range tagTemplateFields {
+			fields[def.ID] = genTagTemplateField(def, int32(len(tagTemplateFields)-i))
+		}
+		_, err := b.DataplexRepo.CreateTagTemplate(parent, b.TagTemplateID, &datacatalogpb.TagTemplate{
+			DisplayName: "Quollio",
+			Fields:      fields,
+		})
+		switch {
+		case err == nil:
+			b.Logger.Info("Created tag template %s", tagTemplateName)
+		case code.Is(err, code.AlreadyExists):
+			// MEMO: Another run created the tag template after the read. It is read again to add the missing fields.
+			b.Logger.Info("Tag template %s already exists", tagTemplateName)
+			tagTemplate, err = b.DataplexRepo.GetTagTemplate(tagTemplateName)
+			if err != nil {
+				return "", err
+			}
+		default:
+			return "", err
+		}
+	}
+	if tagTemplate != nil {
+		for i, def := range tagTemplateFields {
+			if _, ok := tagTemplate.Fields[def.ID]; ok {
+				continue
+			}
+			_, err := b.DataplexRepo.CreateTagTemplateField(tagTemplateName, def.ID, genTagTemplateField(def, int32(len(tagTemplateFields)-i)))
+			if code.Is(err, code.AlreadyExists) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			b.Logger.Info("Added field %s to tag template %s", def.ID, tagTemplateName)
+		}
+	}
+	if b.ensuredTagTemplates == nil {
+		b.ensuredTagTemplates = make(map[string]bool)
+	}
+	b.ensuredTagTemplates[tagTemplateName] = true
+	return tagTemplateName, nil
+}
+
+// GenTagFields returns the values of the managed tag for the asset. Empty values are omitted.
+// The tags are written by name. The IDs are used for the tags that are not in the dictionary.
+func GenTagFields(asset qdc.Data, assetURLTemplate string, tagDictionary qdc.TagDictionary) map[string]*datacatalogpb.TagField {
+	fields := make(map[string]*datacatalogpb.TagField)
+	setString := func(fieldID, value string) {
+		if value != "" {
+			fields[fieldID] = &datacatalogpb.TagField{Kind: &datacatalogpb.TagField_StringValue{StringValue: value}}
+		}
+	}
+	setString(TagFieldLogicalName, asset.LogicalName)
+	setString(TagFieldAssetID, asset.ID)
+	setString(TagFieldTags, strings.Join(tagDictionary.TagNames(asset), ","))
+	setString(TagFieldLastUpdatedBy, strings.Join(asset.UpdatedBy, ","))
+	if assetURLTemplate != "" && asset.ID != "" {
+		setString(TagFieldDeepLink, utils.GenAssetURL(assetURLTemplate, asset.ID))
+	}
+	if !asset.UpdatedAt.IsZero() {
+		fields[TagFieldLastUpdatedAt] = &datacatalogpb.TagField{Kind: &datacatalogpb.TagField_TimestampValue{TimestampValue: timestamppb.New(asset.UpdatedAt)}}
+	}
+	return fields
+}
+
+func isSameTagFields(current, desired map[string]*datacatalogpb.TagField) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for fieldID, desiredField := range desired {
+		currentField, ok := current[fieldID]
+		if !ok {
+			return false
+		}
+		if currentField.GetStringValue() != desiredField.GetStringValue() {
+			return false
+		}
+		if currentField.GetTimestampValue().AsTime() != desiredField.GetTimestampValue().AsTime() {
+			return false
+		}
+	}
+	return true
+}
+
+// genSchemaFieldPaths returns the paths of the fields in the schema. The sub-fields of RECORD are joined by dots such as `address.city`.
+func genSchemaFieldPaths(schema bq.Schema) map[string]bool {
+	paths := make(map[string]bool)
+	var walk func(prefix string, fields bq.Schema)
+	walk = func(prefix string, fields bq.Schema) {
+		for _, field := range fields {
+			if field == nil {
+				continue
+			}
+			fieldPath := prefix + field.Name
+			paths[fieldPath] = true
+			walk(fieldPath+".", field.Schema)
+		}
+	}
+	walk("", schema)
+	return paths
+}
+
+// splitColumnsBySchema returns the column assets that are in the schema, and the ones that aren't.
+// QDIC can have the columns that were dropped from the table.
+func splitColumnsBySchema(columnAssets []qdc.Data, schema bq.Schema) ([]qdc.Data, []qdc.Data) {
+	fieldPaths := genSchemaFieldPaths(schema)
+	var existing, missing []qdc.Data
+	for _, columnAsset := range columnAssets {
+		if fieldPaths[columnAsset.PhysicalName] {
+			existing = append(existing, columnAsset)
+			continue
+		}
+		missing = append(missing, columnAsset)
+	}
+	return existing, missing
+}
+
+// syncEntryTags attaches the managed tags to the table entry and its columns in the schema.
+// The tags of the assets with CLEAR action are deleted. Assets with SKIP action are left as they are.
+func (b *BigQueryConnector) syncEntryTags(entry *datacatalogpb.Entry, tagTemplateName, tableFQN string, tableAsset qdc.Data, columnAssets []qdc.Data, schema bq.Schema) error {
+	currentTags, err := b.DataplexRepo.ListTags(entry.Name)
+	if err != nil {
+		return err
+	}
+	mapTagByColumn := make(map[string]*datacatalogpb.Tag)
+	for _, tag := range currentTags {
+		if tag.Template == tagTemplateName {
+			mapTagByColumn[tag.GetColumn()] = tag
+		}
+	}
+	existingColumnAssets, missingColumnAssets := splitColumnsBySchema(columnAssets, schema)
+	for _, columnAsset := range missingColumnAssets {
+		b.Report.Add(report.WARNING, "dataplex", fmt.Sprintf("%s.%s", tableFQN, columnAsset.PhysicalName), "tag", "The column is not found in the table schema. Skipped the tag.")
+	}
+	// MEMO: The table tag has empty column.
+	targets := map[string]qdc.Data{"": tableAsset}
+	for _, columnAsset := range existingColumnAssets {
+		targets[columnAsset.PhysicalName] = columnAsset
+	}
+	for column, asset := range targets {
+		action := b.AssetStatePolicy.GetAction(asset.IsLost, asset.IsArchived)
+		currentTag, hasTag := mapTagByColumn[column]
+		switch {
+		case action == utils.AssetStateSkip:
+			continue
+		case action == utils.AssetStateClear:
+			if !hasTag {
+				continue
+			}
+			if err := b.DataplexRepo.DeleteTag(currentTag.Name); err != nil {
+				return err
+			}
+			b.Logger.Debug("Deleted tag. entry: %s column: %s", entry.Name, column)
+			continue
+		}
+		fields := GenTagFields(asset, b.DescriptionLimiter.AssetURLTemplate, b.TagDictionary)
+		if hasTag {
+			if isSameTagFields(currentTag.Fields, fields) {
+				continue
+			}
+			currentTag.Fields = fields
+			if _, err := b.DataplexRepo.UpdateTag(currentTag); err != nil {
+				return err
+			}
+			b.Logger.Debug("Updated tag. entry: %s column: %s", entry.Name, column)
+			continue
+		}
+		tag := &datacatalogpb.Tag{
+			Template: tagTemplateName,
+			Fields:   fields,
+		}
+		if column != "" {
+			tag.Scope = &datacatalogpb.Tag_Column{Column: column}
+		}
+		if _, err := b.DataplexRepo.CreateTag(entry.Name, tag); err != nil {
+			// MEMO: Another run created the tag after the list. Its fields are updated by the next run.
+			if code.Is(err, code.AlreadyExists) {
+				b.Logger.Debug("Tag already exists. entry: %s column: %s", entry.Name, column)
+				continue
+			}
+			return err
+		}
+		b.Logger.Debug("Created tag. entry: %s column: %s", entry.Name, column)
+	}
+	return nil
+}
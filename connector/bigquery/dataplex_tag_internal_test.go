@@ -0,0 +1,110 @@
+package bigquery
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+	"time"
+
+	bq "cloud.google.com/go/bigquery"
+	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGenTagFields(t *testing.T) {
+	updatedAt := time.Date(2024, 4, 10, 9, 0, 0, 0, time.UTC)
+	asset := qdc.Data{
+		ID:          "tbl-1234",
+		LogicalName: "注文",
+		UpdatedBy:   []string{"user-a", "user-b"},
+		UpdatedAt:   updatedAt,
+		RuleTagIds: []qdc.RuleTagIds{
+			{TagGroupId: "tggr-1", ParentTagId: "tag-1"},
+			{TagGroupId: "tggr-1", ParentTagId: "tag-2", ChildTagId: "tag-3"},
+		},
+		ManualTagIds: []qdc.RuleTagIds{
+			{TagGroupId: "tggr-1", ParentTagId: "tag-1"},
+		},
+	}
+	tagDictionary := qdc.NewTagDictionary([]qdc.TagGroup{
+		{ID: "tggr-1", Name: "機密", Tags: []qdc.Tag{
+			{ID: "tag-1", Name: "社外秘"},
+			{ID: "tag-2", Name: "個人情報", ChildTags: []qdc.Tag{{ID: "tag-3", Name: "氏名"}}},
+		}},
+	})
+	fields := GenTagFields(asset, "https://example.com/assets/{id}", tagDictionary)
+	wantStrings := map[string]string{
+		TagFieldLogicalName:   "注文",
+		TagFieldAssetID:       "tbl-1234",
+		TagFieldTags:          "機密/社外秘,機密/個人情報/氏名",
+		TagFieldLastUpdatedBy: "user-a,user-b",
+		TagFieldDeepLink:      "https://example.com/assets/tbl-1234",
+	}
+	for fieldID, want := range wantStrings {
+		if got := fields[fieldID].GetStringValue(); got != want {
+			t.Errorf("%s: want %s but got %s", fieldID, want, got)
+		}
+	}
+	if got := fields[TagFieldLastUpdatedAt].GetTimestampValue().AsTime(); !got.Equal(updatedAt) {
+		t.Errorf("%s: want %v but got %v", TagFieldLastUpdatedAt, updatedAt, got)
+	}
+
+	fields = GenTagFields(asset, "", qdc.TagDictionary{})
+	if got := fields[TagFieldTags].GetStringValue(); got != "tggr-1/tag-1,tggr-1/tag-2/tag-3" {
+		t.Errorf("IDs should be used without the dictionary but got %s", got)
+	}
+
+	fields = GenTagFields(qdc.Data{ID: "tbl-5678"}, "", qdc.TagDictionary{})
+	if len(fields) != 1 {
+		t.Errorf("empty values should be omitted but got %v", fields)
+	}
+}
+
+func TestIsSameTagFields(t *testing.T) {
+	stringField := func(v string) *datacatalogpb.TagField {
+		return &datacatalogpb.TagField{Kind: &datacatalogpb.TagField_StringValue{StringValue: v}}
+	}
+	timestampField := func(ts time.Time) *datacatalogpb.TagField {
+		return &datacatalogpb.TagField{Kind: &datacatalogpb.TagField_TimestampValue{TimestampValue: timestamppb.New(ts)}}
+	}
+	ts := time.Date(2024, 4, 10, 9, 0, 0, 0, time.UTC)
+	base := map[string]*datacatalogpb.TagField{TagFieldAssetID: stringField("tbl-1"), TagFieldLastUpdatedAt: timestampField(ts)}
+	testCases := []struct {
+		name    string
+		desired map[string]*datacatalogpb.TagField
+		want    bool
+	}{
+		{name: "same", desired: map[string]*datacatalogpb.TagField{TagFieldAssetID: stringField("tbl-1"), TagFieldLastUpdatedAt: timestampField(ts)}, want: true},
+		{name: "string changed", desired: map[string]*datacatalogpb.TagField{TagFieldAssetID: stringField("tbl-2"), TagFieldLastUpdatedAt: timestampField(ts)}, want: false},
+		{name: "timestamp changed", desired: map[string]*datacatalogpb.TagField{TagFieldAssetID: stringField("tbl-1"), TagFieldLastUpdatedAt: timestampField(ts.Add(time.Second))}, want: false},
+		{name: "field removed", desired: map[string]*datacatalogpb.TagField{TagFieldAssetID: stringField("tbl-1")}, want: false},
+	}
+	for _, tt := range testCases {
+		if res := isSameTagFields(base, tt.desired); res != tt.want {
+			t.Errorf("%s: want %v but got %v", tt.name, tt.want, res)
+		}
+	}
+}
+
+func TestSplitColumnsBySchema(t *testing.T) {
+	schema := bq.Schema{
+		{Name: "order_id", Type: bq.IntegerFieldType},
+		{Name: "address", Type: bq.RecordFieldType, Schema: bq.Schema{
+			{Name: "city", Type: bq.StringFieldType},
+		}},
+	}
+	columnAssets := []qdc.Data{
+		{ID: "clmn-1", PhysicalName: "order_id"},
+		{ID: "clmn-2", PhysicalName: "address"},
+		{ID: "clmn-3", PhysicalName: "address.city"},
+		{ID: "clmn-4", PhysicalName: "dropped_column"},
+		{ID: "clmn-5", PhysicalName: "city"},
+	}
+	existing, missing := splitColumnsBySchema(columnAssets, schema)
+	if diff := cmp.Diff(columnAssets[:3], existing); diff != "" {
+		t.Errorf("existing mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(columnAssets[3:], missing); diff != "" {
+		t.Errorf("missing mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,175 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultLabelKeyPrefix = "qdic_tag_"
+	maxLabelLength        = 63 // both of keys and values. counted in characters.
+)
+
+// LabelMapping maps QDIC tags to labels of datasets and tables.
+// The keys are put under KeyPrefix, and only the labels under KeyPrefix are owned by the agent.
+type LabelMapping struct {
+	KeyPrefix string             `json:"-"`
+	Mappings  []LabelMappingRule `json:"mappings"`
+}
+
+// LabelMappingRule sets the label on the assets that have the selected QDIC tag.
+// Value is "true" if it's empty.
+type LabelMappingRule struct {
+	qdc.TagSelector
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func LoadLabelMapping(path, keyPrefix string) (LabelMapping, error) {
+	if keyPrefix == "" {
+		keyPrefix = defaultLabelKeyPrefix
+	}
+	if !isLabelSafe(keyPrefix) || !startsWithLetter(keyPrefix) {
+		return LabelMapping{}, fmt.Errorf("invalid label key prefix %s. It must start with a lowercase letter and consist of lowercase letters, numbers, underscores and dashes", keyPrefix)
+	}
+	mapping := LabelMapping{KeyPrefix: keyPrefix}
+	if path == "" {
+		return mapping, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return LabelMapping{}, err
+	}
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return LabelMapping{}, fmt.Errorf("invalid label mapping %s: %s", path, err)
+	}
+	for i, rule := range mapping.Mappings {
+		if rule.TagSelector.IsEmpty() {
+			return LabelMapping{}, fmt.Errorf("mapping %d has none of tag_group_id, tag_id, tag_group_name and tag_name", i)
+		}
+		if NormalizeLabel(rule.Key) == "" {
+			return LabelMapping{}, fmt.Errorf("mapping %d has no valid key", i)
+		}
+	}
+	return mapping, nil
+}
+
+func (m LabelMapping) IsEmpty() bool {
+	return len(m.Mappings) == 0
+}
+
+// TagSelectors returns the selectors of the rules, so that the tag names in them are resolved.
+func (m *LabelMapping) TagSelectors() []*qdc.TagSelector {
+	selectors := make([]*qdc.TagSelector, 0, len(m.Mappings))
+	for i := range m.Mappings {
+		selectors = append(selectors, &m.Mappings[i].TagSelector)
+	}
+	return selectors
+}
+
+// GenLabels returns the labels for the asset. When several rules give the same key, the first one is used.
+func (m LabelMapping) GenLabels(asset qdc.Data) map[string]string {
+	labels := make(map[string]string)
+	for _, rule := range m.Mappings {
+		if !rule.Matches(asset) {
+			continue
+		}
+		key := truncateLabel(m.KeyPrefix + NormalizeLabel(rule.Key))
+		if _, ok := labels[key]; ok {
+			continue
+		}
+		value := "true"
+		if rule.Value != "" {
+			value = truncateLabel(NormalizeLabel(rule.Value))
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// updateTagLabels sets the labels mapped from the QDIC tags. The labels are removed for CLEAR action.
+func (b *BigQueryConnector) updateTagLabels(updater labelUpdater, currentLabels map[string]string, action string, asset qdc.Data) bool {
+	if b.LabelMapping.IsEmpty() {
+		return false
+	}
+	desiredLabels := map[string]string{}
+	if action != utils.AssetStateClear {
+		desiredLabels = b.LabelMapping.GenLabels(asset)
+	}
+	return updateTagLabels(updater, currentLabels, desiredLabels, b.LabelMapping.KeyPrefix, b.DeprecationLabelKey)
+}
+
+// updateTagLabels sets the desired labels and deletes the other labels under the key prefix.
+// protectedKey is never deleted even if it's under the key prefix. It returns true when the labels have to be updated.
+func updateTagLabels(updater labelUpdater, currentLabels, desiredLabels map[string]string, keyPrefix, protectedKey string) bool {
+	shouldBeUpdated := false
+	for key, value := range desiredLabels {
+		if currentValue, ok := currentLabels[key]; ok && currentValue == value {
+			continue
+		}
+		updater.SetLabel(key, value)
+		shouldBeUpdated = true
+	}
+	for key := range currentLabels {
+		if !strings.HasPrefix(key, keyPrefix) || key == protectedKey {
+			continue
+		}
+		if _, ok := desiredLabels[key]; ok {
+			continue
+		}
+		updater.DeleteLabel(key)
+		shouldBeUpdated = true
+	}
+	return shouldBeUpdated
+}
+
+// NormalizeLabel converts the name into the characters allowed in labels.
+// Full-width letters are converted to half-width by NFKC, and international characters such as Japanese are kept.
+// Other characters are replaced with underscores.
+func NormalizeLabel(name string) string {
+	var sb strings.Builder
+	lastIsUnderscore := false
+	for _, r := range norm.NFKC.String(name) {
+		r = unicode.ToLower(r)
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			sb.WriteRune(r)
+			lastIsUnderscore = false
+		case !lastIsUnderscore:
+			sb.WriteRune('_')
+			lastIsUnderscore = true
+		}
+	}
+	return strings.Trim(sb.String(), "_")
+}
+
+func truncateLabel(label string) string {
+	runes := []rune(label)
+	if len(runes) <= maxLabelLength {
+		return label
+	}
+	return string(runes[:maxLabelLength])
+}
+
+func isLabelSafe(s string) bool {
+	for _, r := range s {
+		if !(unicode.IsLetter(r) && !unicode.IsUpper(r)) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func startsWithLetter(s string) bool {
+	for _, r := range s {
+		return unicode.IsLetter(r)
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package bigquery
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeLabelUpdater struct {
+	set     map[string]string
+	deleted []string
+}
+
+func (f *fakeLabelUpdater) SetLabel(name, value string) {
+	f.set[name] = value
+}
+
+func (f *fakeLabelUpdater) DeleteLabel(name string) {
+	f.deleted = append(f.deleted, name)
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{input: "Sensitivity", want: "sensitivity"},
+		{input: "個人情報", want: "個人情報"},
+		{input: "【機密】 レベル 1", want: "機密_レベル_1"},
+		{input: "ＰＩＩ－ｄａｔａ", want: "pii-data"},
+		{input: "cost.center/A", want: "cost_center_a"},
+		{input: "!!!", want: ""},
+	}
+	for _, tt := range testCases {
+		res := NormalizeLabel(tt.input)
+		if res != tt.want {
+			t.Errorf("want %s but got %s. input: %s", tt.want, res, tt.input)
+		}
+	}
+}
+
+func TestLoadLabelMappingInvalidPrefix(t *testing.T) {
+	for _, prefix := range []string{"QDIC_", "1qdic_", "qdic."} {
+		if _, err := LoadLabelMapping("", prefix); err == nil {
+			t.Errorf("expected an error for %s but got nil.", prefix)
+		}
+	}
+}
+
+func TestLabelMappingGenLabels(t *testing.T) {
+	mapping := LabelMapping{
+		KeyPrefix: "qdic_tag_",
+		Mappings: []LabelMappingRule{
+			{TagSelector: qdc.TagSelector{TagID: "tag-pii"}, Key: "機密区分", Value: "個人情報"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, Key: "機密区分", Value: "社外秘"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, Key: "Cost Center"},
+		},
+	}
+	asset := qdc.Data{RuleTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-pii"}}}
+	want := map[string]string{
+		"qdic_tag_機密区分":        "個人情報",
+		"qdic_tag_cost_center": "true",
+	}
+	if diff := cmp.Diff(want, mapping.GenLabels(asset)); diff != "" {
+		t.Errorf("labels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateTagLabels(t *testing.T) {
+	currentLabels := map[string]string{
+		"team":                "finance",
+		"qdic_tag_old":        "true",
+		"qdic_tag_same":       "true",
+		"qdic_tag_changed":    "a",
+		"qdic_tag_deprecated": "true",
+	}
+	desiredLabels := map[string]string{
+		"qdic_tag_same":    "true",
+		"qdic_tag_changed": "b",
+		"qdic_tag_new":     "true",
+	}
+	updater := &fakeLabelUpdater{set: map[string]string{}}
+	res := updateTagLabels(updater, currentLabels, desiredLabels, "qdic_tag_", "qdic_tag_deprecated")
+	if !res {
+		t.Errorf("want true but got false")
+	}
+	if diff := cmp.Diff(map[string]string{"qdic_tag_changed": "b", "qdic_tag_new": "true"}, updater.set); diff != "" {
+		t.Errorf("set labels mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"qdic_tag_old"}, updater.deleted); diff != "" {
+		t.Errorf("deleted labels mismatch (-want +got):\n%s", diff)
+	}
+
+	updater = &fakeLabelUpdater{set: map[string]string{}}
+	if updateTagLabels(updater, desiredLabels, desiredLabels, "qdic_tag_", "") {
+		t.Errorf("want false but got true")
+	}
+}
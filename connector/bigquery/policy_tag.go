@@ -0,0 +1,255 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+
+	bq "cloud.google.com/go/bigquery"
+)
+
+// PolicyTagMapping maps QDIC tags to Data Catalog policy tags.
+// Parent is the location of taxonomies such as `projects/{project}/locations/{location}`, used for taxonomies given by display name.
+type PolicyTagMapping struct {
+	Parent   string                 `json:"parent"`
+	Mappings []PolicyTagMappingRule `json:"mappings"`
+}
+
+// PolicyTagMappingRule maps the selected QDIC tag to a policy tag.
+// Taxonomy and PolicyTag accept either a resource name (projects/...) or a display name.
+// MEMO: A BigQuery column can have only one policy tag. The first matched rule is used.
+type PolicyTagMappingRule struct {
+	qdc.TagSelector
+	Taxonomy  string `json:"taxonomy"`
+	PolicyTag string `json:"policy_tag"`
+
+	resolvedName string
+}
+
+func LoadPolicyTagMapping(path string) (PolicyTagMapping, error) {
+	if path == "" {
+		return PolicyTagMapping{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return PolicyTagMapping{}, err
+	}
+	var mapping PolicyTagMapping
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return PolicyTagMapping{}, fmt.Errorf("invalid policy tag mapping %s: %s", path, err)
+	}
+	for i, rule := range mapping.Mappings {
+		if rule.TagSelector.IsEmpty() {
+			return PolicyTagMapping{}, fmt.Errorf("mapping %d has none of tag_group_id, tag_id, tag_group_name and tag_name", i)
+		}
+		if rule.PolicyTag == "" {
+			return PolicyTagMapping{}, fmt.Errorf("mapping %d has no policy_tag", i)
+		}
+		if !isResourceName(rule.PolicyTag) && rule.Taxonomy == "" {
+			return PolicyTagMapping{}, fmt.Errorf("mapping %d needs taxonomy for the policy tag %s", i, rule.PolicyTag)
+		}
+		if !isResourceName(rule.PolicyTag) && !isResourceName(rule.Taxonomy) && mapping.Parent == "" {
+			return PolicyTagMapping{}, fmt.Errorf("parent is required for the taxonomy %s", rule.Taxonomy)
+		}
+		if isResourceName(rule.PolicyTag) {
+			mapping.Mappings[i].resolvedName = rule.PolicyTag
+		}
+	}
+	return mapping, nil
+}
+
+func isResourceName(name string) bool {
+	return strings.HasPrefix(name, "projects/")
+}
+
+func (m PolicyTagMapping) IsEmpty() bool {
+	return len(m.Mappings) == 0
+}
+
+// TagSelectors returns the selectors of the rules, so that the tag names in them are resolved.
+func (m *PolicyTagMapping) TagSelectors() []*qdc.TagSelector {
+	selectors := make([]*qdc.TagSelector, 0, len(m.Mappings))
+	for i := range m.Mappings {
+		selectors = append(selectors, &m.Mappings[i].TagSelector)
+	}
+	return selectors
+}
+
+// FindPolicyTag returns the resource name of the policy tag for the asset. It returns empty string if no rule matches.
+func (m PolicyTagMapping) FindPolicyTag(asset qdc.Data) string {
+	for _, rule := range m.Mappings {
+		if rule.resolvedName != "" && rule.Matches(asset) {
+			return rule.resolvedName
+		}
+	}
+	return ""
+}
+
+// IsManaged returns true if the policy tag is one of the targets of the mapping.
+func (m PolicyTagMapping) IsManaged(policyTagName string) bool {
+	for _, rule := range m.Mappings {
+		if rule.resolvedName == policyTagName {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePolicyTags looks up the resource names of the policy tags given by display name.
+// Missing taxonomies and policy tags are created if PolicyTagCreateMissing is true. Otherwise the rule is ignored.
+func (b *BigQueryConnector) resolvePolicyTags() error {
+	taxonomyNames := make(map[string]string)
+	policyTagNames := make(map[string]map[string]string)
+	for i, rule := range b.PolicyTagMapping.Mappings {
+		if rule.resolvedName != "" {
+			continue
+		}
+		taxonomyName, err := b.resolveTaxonomy(rule.Taxonomy, taxonomyNames)
+		if err != nil {
+			return err
+		}
+		if taxonomyName == "" {
+			b.Logger.Warning("Taxonomy %s is not found. The mapping to %s is ignored.", rule.Taxonomy, rule.PolicyTag)
+			b.Report.Add(report.WARNING, "bigquery", rule.Taxonomy, "policy_tag", "Taxonomy is not found.")
+			continue
+		}
+		if _, ok := policyTagNames[taxonomyName]; !ok {
+			policyTags, err := b.DataplexRepo.ListPolicyTags(taxonomyName)
+			if err != nil {
+				return err
+			}
+			policyTagNames[taxonomyName] = make(map[string]string)
+			for _, policyTag := range policyTags {
+				policyTagNames[taxonomyName][policyTag.DisplayName] = policyTag.Name
+			}
+		}
+		if name, ok := policyTagNames[taxonomyName][rule.PolicyTag]; ok {
+			b.PolicyTagMapping.Mappings[i].resolvedName = name
+			continue
+		}
+		if !b.PolicyTagCreateMissing {
+			b.Logger.Warning("Policy tag %s is not found in %s. The mapping is ignored.", rule.PolicyTag, taxonomyName)
+			b.Report.Add(report.WARNING, "bigquery", taxonomyName, "policy_tag", fmt.Sprintf("Policy tag %s is not found.", rule.PolicyTag))
+			continue
+		}
+		policyTagName, err := b.createPolicyTag(taxonomyName, rule.PolicyTag)
+		if err != nil {
+			return err
+		}
+		policyTagNames[taxonomyName][rule.PolicyTag] = policyTagName
+		b.PolicyTagMapping.Mappings[i].resolvedName = policyTagName
+	}
+	return nil
+}
+
+func (b *BigQueryConnector) resolveTaxonomy(taxonomy string, taxonomyNames map[string]string) (string, error) {
+	if isResourceName(taxonomy) {
+		return taxonomy, nil
+	}
+	if len(taxonomyNames) == 0 {
+		taxonomies, err := b.DataplexRepo.ListTaxonomies(b.PolicyTagMapping.Parent)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range taxonomies {
+			taxonomyNames[t.DisplayName] = t.Name
+		}
+	}
+	if name, ok := taxonomyNames[taxonomy]; ok {
+		return name, nil
+	}
+	if !b.PolicyTagCreateMissing {
+		return "", nil
+	}
+	created, err := b.DataplexRepo.CreateTaxonomy(b.PolicyTagMapping.Parent, taxonomy)
+	if code.Is(err, code.AlreadyExists) {
+		// MEMO: Another run created the taxonomy after the list. It is listed again to get the name.
+		taxonomies, listErr := b.DataplexRepo.ListTaxonomies(b.PolicyTagMapping.Parent)
+		if listErr != nil {
+			return "", listErr
+		}
+		for _, t := range taxonomies {
+			taxonomyNames[t.DisplayName] = t.Name
+		}
+		if name, ok := taxonomyNames[taxonomy]; ok {
+			return name, nil
+		}
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+	b.Logger.Info("Created taxonomy %s in %s", taxonomy, b.PolicyTagMapping.Parent)
+	taxonomyNames[taxonomy] = created.Name
+	return created.Name, nil
+}
+
+// createPolicyTag creates the policy tag and returns its name.
+// If another run created it, the existing policy tag is looked up instead.
+func (b *BigQueryConnector) createPolicyTag(taxonomyName, displayName string) (string, error) {
+	policyTag, err := b.DataplexRepo.CreatePolicyTag(taxonomyName, displayName)
+	if code.Is(err, code.AlreadyExists) {
+		policyTags, listErr := b.DataplexRepo.ListPolicyTags(taxonomyName)
+		if listErr != nil {
+			return "", listErr
+		}
+		for _, p := range policyTags {
+			if p.DisplayName == displayName {
+				return p.Name, nil
+			}
+		}
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+	b.Logger.Info("Created policy tag %s in %s", displayName, taxonomyName)
+	return policyTag.Name, nil
+}
+
+// GetPolicyTagUpdatedSchema sets the policy tags mapped from the QDIC tags of the columns.
+// Policy tags that are not managed by the mapping are kept if keepUnmanaged is true.
+func GetPolicyTagUpdatedSchema(mapping PolicyTagMapping, keepUnmanaged bool, assetStatePolicy utils.AssetStatePolicy, columnAssets []qdc.Data, tableSchemas []*bq.FieldSchema) bool {
+	if mapping.IsEmpty() {
+		return false
+	}
+	shouldSchemaUpdated := false
+	mapColumnAssetByColumnName := MapColumnAssetByColumnName(columnAssets)
+	for _, schemaField := range tableSchemas {
+		columnAsset, ok := mapColumnAssetByColumnName[schemaField.Name]
+		if !ok {
+			continue
+		}
+		action := assetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			continue
+		}
+		var currentPolicyTag string
+		if schemaField.PolicyTags != nil && len(schemaField.PolicyTags.Names) > 0 {
+			currentPolicyTag = schemaField.PolicyTags.Names[0]
+		}
+		if currentPolicyTag != "" && keepUnmanaged && !mapping.IsManaged(currentPolicyTag) {
+			continue
+		}
+		var desiredPolicyTag string
+		if action != utils.AssetStateClear {
+			desiredPolicyTag = mapping.FindPolicyTag(columnAsset)
+		}
+		if desiredPolicyTag == currentPolicyTag {
+			continue
+		}
+		if desiredPolicyTag == "" {
+			// MEMO: An empty list removes the policy tag from the column.
+			schemaField.PolicyTags = &bq.PolicyTagList{}
+		} else {
+			schemaField.PolicyTags = &bq.PolicyTagList{Names: []string{desiredPolicyTag}}
+		}
+		shouldSchemaUpdated = true
+	}
+	return shouldSchemaUpdated
+}
@@ -0,0 +1,134 @@
+package bigquery
+
+import (
+	"os"
+	"path/filepath"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	bq "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testPIIPolicyTag    = "projects/p/locations/us/taxonomies/1/policyTags/11"
+	testSecretPolicyTag = "projects/p/locations/us/taxonomies/1/policyTags/12"
+	testOtherPolicyTag  = "projects/p/locations/us/taxonomies/2/policyTags/21"
+)
+
+func TestLoadPolicyTagMapping(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "resource name and display name",
+			content: `{"parent": "projects/p/locations/us", "mappings": [{"tag_id": "tag-1", "policy_tag": "` + testPIIPolicyTag + `"}, {"tag_group_id": "tggr-1", "taxonomy": "Sensitivity", "policy_tag": "Secret"}]}`,
+		},
+		{
+			name:    "no tag selector",
+			content: `{"mappings": [{"policy_tag": "` + testPIIPolicyTag + `"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "display name without taxonomy",
+			content: `{"parent": "projects/p/locations/us", "mappings": [{"tag_id": "tag-1", "policy_tag": "Secret"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "taxonomy display name without parent",
+			content: `{"mappings": [{"tag_id": "tag-1", "taxonomy": "Sensitivity", "policy_tag": "Secret"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			content: `{"mappings": [`,
+			wantErr: true,
+		},
+	}
+	for i, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i))+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPolicyTagMapping(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("want error %v but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetPolicyTagUpdatedSchema(t *testing.T) {
+	mapping := PolicyTagMapping{
+		Mappings: []PolicyTagMappingRule{
+			{TagSelector: qdc.TagSelector{TagID: "tag-pii"}, resolvedName: testPIIPolicyTag},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-secret"}, resolvedName: testSecretPolicyTag},
+		},
+	}
+	piiColumn := qdc.Data{PhysicalName: "email", ManualTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-pii"}}}
+	plainColumn := qdc.Data{PhysicalName: "plain"}
+
+	testCases := []struct {
+		name          string
+		keepUnmanaged bool
+		columnAsset   qdc.Data
+		current       *bq.PolicyTagList
+		want          *bq.PolicyTagList
+		wantUpdate    bool
+	}{
+		{
+			name:        "set the mapped policy tag",
+			columnAsset: piiColumn,
+			want:        &bq.PolicyTagList{Names: []string{testPIIPolicyTag}},
+			wantUpdate:  true,
+		},
+		{
+			name:        "already set",
+			columnAsset: piiColumn,
+			current:     &bq.PolicyTagList{Names: []string{testPIIPolicyTag}},
+			want:        &bq.PolicyTagList{Names: []string{testPIIPolicyTag}},
+		},
+		{
+			name:        "remove the managed policy tag",
+			columnAsset: plainColumn,
+			current:     &bq.PolicyTagList{Names: []string{testSecretPolicyTag}},
+			want:        &bq.PolicyTagList{},
+			wantUpdate:  true,
+		},
+		{
+			name:          "keep the unmanaged policy tag",
+			keepUnmanaged: true,
+			columnAsset:   piiColumn,
+			current:       &bq.PolicyTagList{Names: []string{testOtherPolicyTag}},
+			want:          &bq.PolicyTagList{Names: []string{testOtherPolicyTag}},
+		},
+		{
+			name:        "replace the unmanaged policy tag",
+			columnAsset: piiColumn,
+			current:     &bq.PolicyTagList{Names: []string{testOtherPolicyTag}},
+			want:        &bq.PolicyTagList{Names: []string{testPIIPolicyTag}},
+			wantUpdate:  true,
+		},
+		{
+			name:        "skip lost column",
+			columnAsset: qdc.Data{PhysicalName: "email", IsLost: true, ManualTagIds: piiColumn.ManualTagIds},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := []*bq.FieldSchema{{Name: tt.columnAsset.PhysicalName, PolicyTags: tt.current}}
+			res := GetPolicyTagUpdatedSchema(mapping, tt.keepUnmanaged, utils.DefaultAssetStatePolicy(), []qdc.Data{tt.columnAsset}, schema)
+			if res != tt.wantUpdate {
+				t.Errorf("want %v but got %v", tt.wantUpdate, res)
+			}
+			if diff := cmp.Diff(tt.want, schema[0].PolicyTags); diff != "" {
+				t.Errorf("policy tags mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
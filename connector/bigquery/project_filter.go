@@ -0,0 +1,74 @@
+package bigquery
+
+import (
+	"fmt"
+	"path"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+)
+
+// ProjectFilter selects the Google Cloud projects to be synced by the project IDs.
+// The patterns are comma-separated and may contain the wildcards of path.Match such as `prod-*`.
+// A project is synced if it matches Allow (or Allow is empty) and doesn't match Deny.
+type ProjectFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+func NewProjectFilter(allow, deny string) (ProjectFilter, error) {
+	allowPatterns, err := parseProjectPatterns(allow)
+	if err != nil {
+		return ProjectFilter{}, fmt.Errorf("invalid project allow list %s: %s", allow, err)
+	}
+	denyPatterns, err := parseProjectPatterns(deny)
+	if err != nil {
+		return ProjectFilter{}, fmt.Errorf("invalid project deny list %s: %s", deny, err)
+	}
+	return ProjectFilter{Allow: allowPatterns, Deny: denyPatterns}, nil
+}
+
+func parseProjectPatterns(patterns string) ([]string, error) {
+	var res []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+		res = append(res, pattern)
+	}
+	return res, nil
+}
+
+func matchProject(patterns []string, projectID string) bool {
+	for _, pattern := range patterns {
+		// MEMO: The patterns are validated in NewProjectFilter.
+		if ok, _ := path.Match(pattern, projectID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ProjectFilter) IsTarget(projectID string) bool {
+	if len(f.Allow) > 0 && !matchProject(f.Allow, projectID) {
+		return false
+	}
+	return !matchProject(f.Deny, projectID)
+}
+
+// FilterProjectAssets returns the project assets to be synced and the IDs of the projects that are skipped.
+func (f ProjectFilter) FilterProjectAssets(projectAssets []qdc.Data) ([]qdc.Data, []string) {
+	var targets []qdc.Data
+	var skipped []string
+	for _, projectAsset := range projectAssets {
+		if f.IsTarget(projectAsset.PhysicalName) {
+			targets = append(targets, projectAsset)
+			continue
+		}
+		skipped = append(skipped, projectAsset.PhysicalName)
+	}
+	return targets, skipped
+}
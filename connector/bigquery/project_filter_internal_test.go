@@ -0,0 +1,60 @@
+package bigquery
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewProjectFilter(t *testing.T) {
+	filter, err := NewProjectFilter(" prod-*, analytics ,", "prod-sandbox")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := ProjectFilter{Allow: []string{"prod-*", "analytics"}, Deny: []string{"prod-sandbox"}}
+	if diff := cmp.Diff(want, filter); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := NewProjectFilter("prod-[", ""); err == nil {
+		t.Errorf("want error for the invalid pattern")
+	}
+}
+
+func TestProjectFilterIsTarget(t *testing.T) {
+	testCases := []struct {
+		name      string
+		filter    ProjectFilter
+		projectID string
+		want      bool
+	}{
+		{name: "no lists", filter: ProjectFilter{}, projectID: "any-project", want: true},
+		{name: "allowed by pattern", filter: ProjectFilter{Allow: []string{"prod-*"}}, projectID: "prod-sales", want: true},
+		{name: "not allowed", filter: ProjectFilter{Allow: []string{"prod-*"}}, projectID: "dev-sales", want: false},
+		{name: "denied", filter: ProjectFilter{Deny: []string{"dev-*"}}, projectID: "dev-sales", want: false},
+		{name: "deny takes precedence", filter: ProjectFilter{Allow: []string{"prod-*"}, Deny: []string{"prod-sandbox"}}, projectID: "prod-sandbox", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.IsTarget(tc.projectID); got != tc.want {
+				t.Errorf("want %v but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterProjectAssets(t *testing.T) {
+	filter := ProjectFilter{Allow: []string{"prod-*"}}
+	projectAssets := []qdc.Data{
+		{ID: "schm-1", PhysicalName: "prod-sales"},
+		{ID: "schm-2", PhysicalName: "dev-sales"},
+		{ID: "schm-3", PhysicalName: "prod-finance"},
+	}
+	targets, skipped := filter.FilterProjectAssets(projectAssets)
+	if diff := cmp.Diff([]qdc.Data{projectAssets[0], projectAssets[2]}, targets); diff != "" {
+		t.Errorf("targets mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"dev-sales"}, skipped); diff != "" {
+		t.Errorf("skipped mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
 
+	"quollio-reverse-agent/common/code"
 	"quollio-reverse-agent/common/logger"
+	"quollio-reverse-agent/common/report"
 	"quollio-reverse-agent/common/utils"
 	"quollio-reverse-agent/repository/denodo/odbc"
 	"quollio-reverse-agent/repository/denodo/odbc/models"
@@ -22,19 +25,46 @@ type DenodoConnector struct {
 	CompanyID            string
 	DenodoHostName       string
 	AssetCreatedBy       string
+	AssetFilter          qdc.AssetFilter
+	AssetStatePolicy     utils.AssetStatePolicy
+	DescriptionLimiter   utils.DescriptionLimiter
 	OverwriteMode        string
 	PrefixForUpdate      string
 	DenodoQueryTargetDBs []string
+	TagMapping           TagMapping
+	TagDictionary        qdc.TagDictionary
+	LogicalNameMode      string
+	Report               *report.Report
 	Logger               *logger.BuiltinLogger
+
+	denodoTagIDs      map[string]int
+	denodoCategoryIDs map[string]int
+	denodoPropertyIDs map[string]int
 }
 
-func NewDenodoConnector(prefixForUpdate, overwriteMode string, logger *logger.BuiltinLogger) (DenodoConnector, error) {
+func NewDenodoConnector(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, logger *logger.BuiltinLogger) (DenodoConnector, error) {
 
 	qdcBaseURL := os.Getenv("QDC_BASE_URL")
 	qdcClientID := os.Getenv("QDC_CLIENT_ID")
 	qdcClientSecret := os.Getenv("QDC_CLIENT_SECRET")
 	assetCreatedBy := os.Getenv("QDC_ASSET_CREATED_BY")
 	companyId := os.Getenv("COMPANY_ID")
+	assetFilter, err := qdc.NewAssetFilter(os.Getenv("QDC_ASSET_FILTER_INCLUDE"), os.Getenv("QDC_ASSET_FILTER_EXCLUDE"))
+	if err != nil {
+		return DenodoConnector{}, fmt.Errorf("Failed to initialize AssetFilter in Denodo Connector %s", err)
+	}
+	descriptionLimiter, err := utils.NewDescriptionLimiter(os.Getenv("DESCRIPTION_LENGTH_LIMITS"), os.Getenv("QDC_ASSET_URL_TEMPLATE"))
+	if err != nil {
+		return DenodoConnector{}, fmt.Errorf("Failed to initialize DescriptionLimiter in Denodo Connector %s", err)
+	}
+	tagMapping, err := LoadTagMapping(os.Getenv("DENODO_TAG_MAPPING_FILE"))
+	if err != nil {
+		return DenodoConnector{}, fmt.Errorf("Failed to load TagMapping in Denodo Connector %s", err)
+	}
+	logicalNameMode, err := parseLogicalNameMode(os.Getenv("DENODO_LOGICAL_NAME_MODE"))
+	if err != nil {
+		return DenodoConnector{}, fmt.Errorf("Failed to parse DENODO_LOGICAL_NAME_MODE in Denodo Connector %s", err)
+	}
 
 	denodoClientID := os.Getenv("DENODO_CLIENT_ID")
 	denodoClientSecret := os.Getenv("DENODO_CLIENT_SECRET")
@@ -68,9 +98,15 @@ func NewDenodoConnector(prefixForUpdate, overwriteMode string, logger *logger.Bu
 		CompanyID:            companyId,
 		DenodoHostName:       denodoHostName,
 		AssetCreatedBy:       assetCreatedBy,
+		AssetFilter:          assetFilter,
+		AssetStatePolicy:     assetStatePolicy,
+		DescriptionLimiter:   descriptionLimiter,
 		OverwriteMode:        overwriteMode,
 		PrefixForUpdate:      prefixForUpdate,
 		DenodoQueryTargetDBs: denodoQueryTargetList,
+		TagMapping:           tagMapping,
+		LogicalNameMode:      logicalNameMode,
+		Report:               report.NewReport(),
 		Logger:               logger,
 	}
 	return connector, nil
@@ -78,6 +114,7 @@ func NewDenodoConnector(prefixForUpdate, overwriteMode string, logger *logger.Bu
 
 func (d *DenodoConnector) ReflectMetadataToDataCatalog() error {
 	defer d.DenodoDBClient.Conn.DB.Close()
+	defer d.Report.Print(d.Logger)
 	d.Logger.Info("Get Denodo assets from QDIC")
 	rootAssets, err := d.QDCExternalAPIClient.GetAllRootAssets("denodo", d.AssetCreatedBy)
 	if err != nil {
@@ -87,14 +124,17 @@ func (d *DenodoConnector) ReflectMetadataToDataCatalog() error {
 	// MEMO: Filter db assets by a parameter.
 	targetRootAssets := getFilteredRootAssets(d.DenodoQueryTargetDBs, rootAssets)
 
-	rootAssetsMap := convertQdcAssetListToMap(targetRootAssets)
+	// MEMO: Child assets are fetched from the parents in the scope of AssetFilter. The columns are fetched from the target tables,
+	// and the include conditions that matched the table aren't applied to its columns again.
+	rootAssetsMap := convertQdcAssetListToMap(d.AssetFilter.FilterAssets(targetRootAssets))
 
 	d.Logger.Info("Get table assets from schema assets")
-	tableAssets, err := d.QDCExternalAPIClient.GetAllChildAssetsByID(targetRootAssets)
+	tableAssets, err := d.QDCExternalAPIClient.GetAllChildAssetsByID(d.AssetFilter.FilterAssetsInScope(targetRootAssets))
 	if err != nil {
 		d.Logger.Error("Failed to GetAllChildAssetsByID for tableAssets: %s", err.Error())
 		return err
 	}
+	tableAssets = d.AssetFilter.FilterAssets(tableAssets)
 	tableAssetsMap := convertQdcAssetListToMap(tableAssets)
 
 	d.Logger.Info("Get column assets from table assets")
@@ -103,7 +143,7 @@ func (d *DenodoConnector) ReflectMetadataToDataCatalog() error {
 		d.Logger.Error("Failed to GetAllChildAssetsByID for tableAssets: %s", err.Error())
 		return err
 	}
-	columnAssetsMap := convertQdcAssetListToMap(columnAssets)
+	columnAssetsMap := convertQdcAssetListToMap(d.AssetFilter.FilterAssetsInScope(columnAssets))
 
 	d.Logger.Info("Start to ReflectVdpMetadataToDataCatalog. Will update VDP resources")
 	err = d.ReflectVdpMetadataToDataCatalog(rootAssetsMap, tableAssetsMap, columnAssetsMap)
@@ -141,16 +181,14 @@ func (d *DenodoConnector) ReflectVdpMetadataToDataCatalog(qdcRootAssetsMap, qdcT
 		d.Logger.Info("Start to update denodo database assets")
 		databaseGlobalID := utils.GetGlobalId(d.CompanyID, d.DenodoHostName, vdpDatabase.DatabaseName, "schema")
 		if qdcDatabaseAsset, ok := qdcRootAssetsMap[databaseGlobalID]; ok {
-			if qdcDatabaseAsset.IsLost {
-				d.Logger.Debug("Skip database update because it is lost in qdc : %s", qdcDatabaseAsset.PhysicalName)
-				continue
-			}
-			if shouldUpdateDenodoVdpDatabase(d.PrefixForUpdate, d.OverwriteMode, vdpDatabase, qdcDatabaseAsset) {
-				descForUpdate := genUpdateString(qdcDatabaseAsset.LogicalName, qdcDatabaseAsset.Description)
-				descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
+			action := d.AssetStatePolicy.GetAction(qdcDatabaseAsset.IsLost, qdcDatabaseAsset.IsArchived)
+			shouldBeUpdated := shouldUpdateDenodoVdpDatabase(d.PrefixForUpdate, d.OverwriteMode, vdpDatabase, qdcDatabaseAsset)
+			if action == utils.AssetStateSkip {
+				d.Logger.Debug("Skip database update because it is lost or archived in qdc : %s", qdcDatabaseAsset.PhysicalName)
+			} else if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoVdpDatabaseDescription, vdpDatabase.DatabaseName, action, vdpDatabase.Description.String, qdcDatabaseAsset, shouldBeUpdated); ok {
 				err := d.DenodoDBClient.UpdateVdpDatabaseDesc(vdpDatabase.DatabaseName, descWithPrefix)
 				if err != nil {
-					if isPrivilegesErr(err.Error()) {
+					if code.Is(err, code.Permission) {
 						d.Logger.Warning("Failed to update DB due to permission problem. Error: %s, DB Name: %s", err.Error(), vdpDatabase.DatabaseName)
 					} else {
 						return err
@@ -170,16 +208,16 @@ func (d *DenodoConnector) ReflectVdpMetadataToDataCatalog(qdcRootAssetsMap, qdcT
 			tableGlobalID := utils.GetGlobalId(d.CompanyID, d.DenodoHostName, tableFQN, "table")
 			d.Logger.Debug("Will update table if condition is true. GlobalID: %s. DBName: %s TableName: %s ", tableGlobalID, vdpTableAsset.DatabaseName, vdpTableAsset.ViewName)
 			if qdcTableAsset, ok := qdcTableAssetsMap[tableGlobalID]; ok {
-				if qdcTableAsset.IsLost {
-					d.Logger.Debug("Skip table update because it is lost in qdc : %s", qdcTableAsset.PhysicalName)
+				action := d.AssetStatePolicy.GetAction(qdcTableAsset.IsLost, qdcTableAsset.IsArchived)
+				if action == utils.AssetStateSkip {
+					d.Logger.Debug("Skip table update because it is lost or archived in qdc : %s", qdcTableAsset.PhysicalName)
 					continue
 				}
-				if shouldUpdateDenodoVdpTable(d.PrefixForUpdate, d.OverwriteMode, vdpTableAsset, qdcTableAsset) {
-					descForUpdate := genUpdateString(qdcTableAsset.LogicalName, qdcTableAsset.Description)
-					descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
+				shouldBeUpdated := shouldUpdateDenodoVdpTable(d.PrefixForUpdate, d.OverwriteMode, vdpTableAsset, qdcTableAsset)
+				if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoVdpViewDescription, fmt.Sprintf("%s.%s", vdpTableAsset.DatabaseName, vdpTableAsset.ViewName), action, vdpTableAsset.Description.String, qdcTableAsset, shouldBeUpdated); ok {
 					err := d.DenodoDBClient.UpdateVdpTableDesc(vdpTableAsset, descWithPrefix)
 					if err != nil {
-						if isPrivilegesErr(err.Error()) {
+						if code.Is(err, code.Permission) {
 							d.Logger.Warning("Failed to update Table due to permission problem. Error: %s, DB Name: %s Table Name: %s", err.Error(), vdpTableAsset.DatabaseName, vdpTableAsset.ViewName)
 						} else {
 							return err
@@ -198,21 +236,21 @@ func (d *DenodoConnector) ReflectVdpMetadataToDataCatalog(qdcRootAssetsMap, qdcT
 			columnFQN := fmt.Sprint(vdpDatabase.DatabaseName, vdpColumnAsset.ViewName, vdpColumnAsset.ColumnName)
 			columnGlobalID := utils.GetGlobalId(d.CompanyID, d.DenodoHostName, columnFQN, "column")
 			if qdcColumnAsset, ok := qdcColumnAssetsMap[columnGlobalID]; ok {
-				if qdcColumnAsset.IsLost {
-					d.Logger.Debug("Skip column update because it is lost in qdc : %s", qdcColumnAsset.PhysicalName)
+				action := d.AssetStatePolicy.GetAction(qdcColumnAsset.IsLost, qdcColumnAsset.IsArchived)
+				if action == utils.AssetStateSkip {
+					d.Logger.Debug("Skip column update because it is lost or archived in qdc : %s", qdcColumnAsset.PhysicalName)
 					continue
 				}
 				if vdpColumnAsset.ViewType != 1 {
 					d.Logger.Debug("Skip update view. only derived view will be updated. database name: %s, table name: %s column name: %s", vdpColumnAsset.DatabaseName, vdpColumnAsset.ViewName, vdpColumnAsset.ColumnName)
 					continue
 				}
-				if shouldUpdateDenodoVdpColumn(d.PrefixForUpdate, d.OverwriteMode, vdpColumnAsset, qdcColumnAsset) {
+				shouldBeUpdated := shouldUpdateDenodoVdpColumn(d.PrefixForUpdate, d.OverwriteMode, vdpColumnAsset, qdcColumnAsset)
+				if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoVdpColumnDescription, fmt.Sprintf("%s.%s.%s", vdpColumnAsset.DatabaseName, vdpColumnAsset.ViewName, vdpColumnAsset.ColumnName), action, vdpColumnAsset.ColumnRemarks.String, qdcColumnAsset, shouldBeUpdated); ok {
 					d.Logger.Debug("Will update column. GlobalID: %s. DBName: %s TableName: %s ColumnName: %s", columnGlobalID, vdpColumnAsset.DatabaseName, vdpColumnAsset.ViewName, vdpColumnAsset.ColumnName)
-					descForUpdate := genUpdateString(qdcColumnAsset.LogicalName, qdcColumnAsset.Description)
-					descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
 					err := d.DenodoDBClient.UpdateVdpTableColumnDesc(vdpColumnAsset, descWithPrefix)
 					if err != nil {
-						if isPrivilegesErr(err.Error()) {
+						if code.Is(err, code.Permission) {
 							d.Logger.Warning("Failed to update Column due to permission problem. Error: %s, DB Name: %s Table Name: %s Column Name: %s", err.Error(), vdpColumnAsset.DatabaseName, vdpColumnAsset.ViewName, vdpColumnAsset.ColumnName)
 						} else {
 							return err
@@ -228,6 +266,25 @@ func (d *DenodoConnector) ReflectVdpMetadataToDataCatalog(qdcRootAssetsMap, qdcT
 }
 
 func (d *DenodoConnector) ReflectDenodoDataCatalogMetadataToDataCatalog(qdcRootAssetsMap, qdcTableAssetsMap, qdcColumnAssetsMap map[string]qdc.Data) error {
+	if !d.TagMapping.IsEmpty() {
+		d.Logger.Info("Get QDIC tag dictionary")
+		tagDictionary, err := d.QDCExternalAPIClient.GetTagDictionary()
+		if err != nil {
+			d.Logger.Error("Failed to GetTagDictionary: %s", err.Error())
+			return err
+		}
+		d.TagDictionary = tagDictionary
+		if err := qdc.ResolveTagNames(tagDictionary, d.TagMapping.TagSelectors()); err != nil {
+			d.Logger.Error("Failed to resolve the tag names in the tag mapping: %s", err.Error())
+			return err
+		}
+		d.Logger.Info("Resolve Data Catalog tags and categories in the mapping")
+		err = d.resolveDataCatalogTags()
+		if err != nil {
+			d.Logger.Error("Failed to resolveDataCatalogTags: %s", err.Error())
+			return err
+		}
+	}
 	d.Logger.Info("Start to update denodo local database assets")
 	localDatabases, err := d.DenodoRepo.GetLocalDatabases()
 	if err != nil {
@@ -274,6 +331,36 @@ func (d *DenodoConnector) IsSkipUpdateDatabaseByFilter(targetDBName string) bool
 	return false
 }
 
+// genDescForUpdate returns the description that should be written to VDP or Data Catalog resources for the action.
+// shouldBeUpdated is the result of the update condition for active assets.
+// The description is truncated to the limit of the field, and the truncation is reported with the target name.
+func (d *DenodoConnector) genDescForUpdate(field, target, action, currentDesc string, qdcAsset qdc.Data, shouldBeUpdated bool) (string, bool) {
+	format := utils.GetFieldFormat(field)
+	descForUpdate := qdcAsset.Description
+	if d.isLogicalNameInDescription(field) {
+		descForUpdate = genUpdateString(qdcAsset.LogicalName, qdcAsset.Description)
+	}
+	var desc string
+	var ok bool
+	switch action {
+	case utils.AssetStateUpdate:
+		if !shouldBeUpdated {
+			return "", false
+		}
+		desc, ok = utils.RenderDescription(d.PrefixForUpdate, descForUpdate, format), true
+	default:
+		desc, ok = d.AssetStatePolicy.GenDescription(action, d.PrefixForUpdate, d.OverwriteMode, format, currentDesc, descForUpdate)
+	}
+	if !ok || desc == "" {
+		return desc, ok
+	}
+	fitted, truncated := d.DescriptionLimiter.Fit(field, desc, qdcAsset.ID)
+	if truncated {
+		d.Report.Add(report.WARNING, "denodo", target, field, fmt.Sprintf("Truncated from %d to %d characters.", utf8.RuneCountInString(desc), utf8.RuneCountInString(fitted)))
+	}
+	return fitted, true
+}
+
 func convertQdcAssetListToMap(qdcAssetList []qdc.Data) map[string]qdc.Data {
 	mapQDCAsset := make(map[string]qdc.Data)
 	for _, qdcAsset := range qdcAssetList {
@@ -351,7 +438,3 @@ func getFilteredRootAssets(targetDBs []string, qdcRootAssets []qdc.Data) []qdc.D
 	}
 	return targetRootAssets
 }
-
-func isPrivilegesErr(errMessage string) bool {
-	return strings.Contains(errMessage, "The user does not have enough privileges")
-}
@@ -1,24 +1,24 @@
 package denodo
 
 import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
 	"quollio-reverse-agent/common/utils"
-	"quollio-reverse-agent/repository/denodo/rest"
 	"quollio-reverse-agent/repository/denodo/rest/models"
 	"quollio-reverse-agent/repository/qdc"
-	"strings"
 )
 
 func (d *DenodoConnector) ReflectLocalDatabaseDescToDenodo(localDatabase models.Database, dbAssets map[string]qdc.Data) error {
 	databaseGlobalID := utils.GetGlobalId(d.CompanyID, d.DenodoHostName, localDatabase.DatabaseName, "schema")
 	if qdcDBAsset, ok := dbAssets[databaseGlobalID]; ok {
-		if qdcDBAsset.IsLost {
-			d.Logger.Debug("Skip db update because it is lost in qdc : %s", qdcDBAsset.PhysicalName)
+		action := d.AssetStatePolicy.GetAction(qdcDBAsset.IsLost, qdcDBAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			d.Logger.Debug("Skip db update because it is lost or archived in qdc : %s", qdcDBAsset.PhysicalName)
 			return nil
 		}
 
-		if shouldUpdateDenodoLocalDatabase(d.PrefixForUpdate, d.OverwriteMode, localDatabase, qdcDBAsset) {
-			descForUpdate := genUpdateString(qdcDBAsset.LogicalName, qdcDBAsset.Description)
-			descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
+		shouldBeUpdated := shouldUpdateDenodoLocalDatabase(d.PrefixForUpdate, d.OverwriteMode, localDatabase, qdcDBAsset)
+		if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoDataCatalogDatabaseDescription, localDatabase.DatabaseName, action, localDatabase.DatabaseDescription, qdcDBAsset, shouldBeUpdated); ok {
 			putDatabaseInput := models.PutDatabaseInput{
 				DatabaseID:      localDatabase.DatabaseId,
 				Description:     descWithPrefix,
@@ -26,16 +26,10 @@ func (d *DenodoConnector) ReflectLocalDatabaseDescToDenodo(localDatabase models.
 			}
 			err := d.DenodoRepo.UpdateLocalDatabases(putDatabaseInput)
 			if err != nil {
-				code, denodoErr := rest.GetErrorCode(err)
-				if denodoErr != nil {
-					return err
-				}
-				switch code {
-				case 401, 403:
-					d.Logger.Warning("Update database description failed due to the ErrorCode %v Skip update. database name: %s.", code, localDatabase.DatabaseName)
-				default:
+				if code.Decide(err) != code.Skip {
 					return err
 				}
+				d.Logger.Warning("Update database description failed due to the %s error. Skip update. database name: %s.", code.CategoryOf(err), localDatabase.DatabaseName)
 			}
 			d.Logger.Debug("Updated Database description database name. database name: %s", localDatabase.DatabaseName)
 		}
@@ -46,8 +40,9 @@ func (d *DenodoConnector) ReflectLocalDatabaseDescToDenodo(localDatabase models.
 func (d *DenodoConnector) ReflectLocalTableAttributeToDenodo(tableAssets map[string]qdc.Data) error {
 	for _, tableAsset := range tableAssets {
 		qdcDatabaseAsset := qdc.GetSpecifiedAssetFromPath(tableAsset, "schema3")
-		if tableAsset.IsLost {
-			d.Logger.Debug("Skip table update because it is lost in qdc : %s->%s", qdcDatabaseAsset.Name, tableAsset.PhysicalName)
+		action := d.AssetStatePolicy.GetAction(tableAsset.IsLost, tableAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			d.Logger.Debug("Skip table update because it is lost or archived in qdc : %s->%s", qdcDatabaseAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
 		isSkipUpdateDatabaseByFilter := d.IsSkipUpdateDatabaseByFilter(qdcDatabaseAsset.Name)
@@ -60,27 +55,20 @@ func (d *DenodoConnector) ReflectLocalTableAttributeToDenodo(tableAssets map[str
 			d.Logger.Warning("Skip to update table because API doesn't allow japanese letter as an input. Database: %s, Table: %s", qdcDatabaseAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
-		if !qdc.IsAssetContainsValueAsDescription(tableAsset) {
+		if action == utils.AssetStateUpdate && !qdc.IsAssetContainsValueAsDescription(tableAsset) && d.TagMapping.IsEmpty() && !d.shouldSyncLogicalName(tableAsset) {
 			d.Logger.Debug("Skip GetViewDetail and Update View because the description of qdc table asset is empty. Database: %s, Table: %s ", qdcDatabaseAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
 		localViewDetail, err := d.DenodoRepo.GetViewDetails(qdcDatabaseAsset.Name, tableAsset.PhysicalName)
 		if err != nil {
-			code, denodoErr := rest.GetErrorCode(err)
-			if denodoErr != nil {
-				return err
-			}
-			switch code {
-			case 404:
-				d.Logger.Warning("GetViewDetails failed due to the ErrorCode %v Skip this function. database name: %s. table name: %s", code, localViewDetail.DatabaseName, localViewDetail.Name)
-				continue
-			default:
+			if code.Decide(err) != code.Skip {
 				return err
 			}
+			d.Logger.Warning("GetViewDetails failed due to the %s error. Skip this function. database name: %s. table name: %s", code.CategoryOf(err), qdcDatabaseAsset.Name, tableAsset.PhysicalName)
+			continue
 		}
-		if shouldUpdateDenodoLocalTable(d.PrefixForUpdate, d.OverwriteMode, localViewDetail, tableAsset) {
-			descForUpdate := genUpdateString(tableAsset.LogicalName, tableAsset.Description)
-			descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
+		shouldBeUpdated := shouldUpdateDenodoLocalTable(d.PrefixForUpdate, d.OverwriteMode, localViewDetail, tableAsset)
+		if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoDataCatalogViewDescription, fmt.Sprintf("%s.%s", localViewDetail.DatabaseName, localViewDetail.Name), action, localViewDetail.Description, tableAsset, shouldBeUpdated); ok && localViewDetail.InLocal {
 			updateLocalViewInput := models.UpdateLocalViewInput{
 				ID:              localViewDetail.Id,
 				Description:     descWithPrefix,
@@ -88,18 +76,30 @@ func (d *DenodoConnector) ReflectLocalTableAttributeToDenodo(tableAssets map[str
 			}
 			err = d.DenodoRepo.UpdateLocalViewDescription(updateLocalViewInput)
 			if err != nil {
-				code, denodoErr := rest.GetErrorCode(err)
-				if denodoErr != nil {
+				if code.Decide(err) != code.Skip {
 					return err
 				}
-				switch code {
-				case 401, 403:
-					d.Logger.Warning("Update table description failed due to the ErrorCode %v Skip update. database name: %s. table name: %s", code, localViewDetail.DatabaseName, localViewDetail.Name)
-				default:
+				d.Logger.Warning("Update table description failed due to the %s error. Skip update. database name: %s. table name: %s", code.CategoryOf(err), localViewDetail.DatabaseName, localViewDetail.Name)
+			}
+			d.Logger.Debug("Updated table description. database name: %s. table name: %s", localViewDetail.DatabaseName, localViewDetail.Name)
+		}
+		if !d.TagMapping.IsEmpty() && localViewDetail.InLocal {
+			err = d.syncLocalViewTags(localViewDetail, action, tableAsset)
+			if err != nil {
+				if code.Decide(err) != code.Skip {
 					return err
 				}
+				d.Logger.Warning("Update table tags failed due to the %s error. Skip update. database name: %s. table name: %s", code.CategoryOf(err), localViewDetail.DatabaseName, localViewDetail.Name)
+			}
+		}
+		if localViewDetail.InLocal {
+			err = d.syncLocalViewLogicalName(localViewDetail, action, tableAsset)
+			if err != nil {
+				if code.Decide(err) != code.Skip {
+					return err
+				}
+				d.Logger.Warning("Update table logical name failed due to the %s error. Skip update. database name: %s. table name: %s", code.CategoryOf(err), localViewDetail.DatabaseName, localViewDetail.Name)
 			}
-			d.Logger.Debug("Updated table description. database name: %s. table name: %s", localViewDetail.DatabaseName, localViewDetail.Name)
 		}
 	}
 	return nil
@@ -109,8 +109,9 @@ func (d *DenodoConnector) ReflectLocalColumnAttributeToDenodo(columnAssets map[s
 	for _, columnAsset := range columnAssets {
 		qdcDatabaseAsset := qdc.GetSpecifiedAssetFromPath(columnAsset, "schema3")
 		qdcTableAsset := qdc.GetSpecifiedAssetFromPath(columnAsset, "table")
-		if columnAsset.IsLost {
-			d.Logger.Debug("Skip column update because it is lost in qdc : %s->%s->%s", qdcDatabaseAsset.Name, qdcTableAsset.Name, columnAsset.PhysicalName)
+		action := d.AssetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			d.Logger.Debug("Skip column update because it is lost or archived in qdc : %s->%s->%s", qdcDatabaseAsset.Name, qdcTableAsset.Name, columnAsset.PhysicalName)
 			continue
 		}
 
@@ -123,29 +124,22 @@ func (d *DenodoConnector) ReflectLocalColumnAttributeToDenodo(columnAssets map[s
 			d.Logger.Warning("Skip to update table because API doesn't allow japanese letter as an input. Database: %s, Table: %s", qdcDatabaseAsset.Name, qdcTableAsset.Name)
 			continue
 		}
-		if !qdc.IsAssetContainsValueAsDescription(columnAsset) {
+		if action == utils.AssetStateUpdate && !qdc.IsAssetContainsValueAsDescription(columnAsset) && d.TagMapping.IsEmpty() && !d.shouldSyncLogicalName(columnAsset) {
 			d.Logger.Debug("Skip GetViewColumns and Update View Column because the description of qdc column asset is empty. Database: %s, Table: %s, Column:  %s", qdcDatabaseAsset.Name, qdcTableAsset.Name, columnAsset.PhysicalName)
 			continue
 		}
 		localViewColumns, err := d.DenodoRepo.GetViewColumns(qdcDatabaseAsset.Name, qdcTableAsset.Name)
 		if err != nil {
-			code, denodoErr := rest.GetErrorCode(err)
-			if denodoErr != nil {
-				return err
-			}
-			switch code {
-			case 404:
-				d.Logger.Warning("GetViewColumns failed due to the ErrorCode %v Skip the function. database name: %s. table name: %s", code, qdcDatabaseAsset.Name, qdcTableAsset.Name)
-				continue
-			default:
+			if code.Decide(err) != code.Skip {
 				return err
 			}
+			d.Logger.Warning("GetViewColumns failed due to the %s error. Skip the function. database name: %s. table name: %s", code.CategoryOf(err), qdcDatabaseAsset.Name, qdcTableAsset.Name)
+			continue
 		}
 		localViewColumnMap := convertLocalColumnListToMap(localViewColumns)
 		if localViewColumn, ok := localViewColumnMap[columnAsset.PhysicalName]; ok {
-			if shouldUpdateDenodoLocalColumn(d.PrefixForUpdate, d.OverwriteMode, localViewColumn, columnAsset) {
-				descForUpdate := genUpdateString(columnAsset.LogicalName, columnAsset.Description)
-				descWithPrefix := utils.AddPrefixToStringIfNotHas(d.PrefixForUpdate, descForUpdate)
+			shouldBeUpdated := shouldUpdateDenodoLocalColumn(d.PrefixForUpdate, d.OverwriteMode, localViewColumn, columnAsset)
+			if descWithPrefix, ok := d.genDescForUpdate(utils.FieldDenodoDataCatalogFieldDescription, fmt.Sprintf("%s.%s.%s", qdcDatabaseAsset.Name, qdcTableAsset.Name, columnAsset.PhysicalName), action, localViewColumn.Description, columnAsset, shouldBeUpdated); ok && localViewColumn.InLocal {
 				updateLocalViewColumnInput := models.UpdateLocalViewFieldInput{
 					DatabaseName:     qdcDatabaseAsset.Name,
 					FieldDescription: descWithPrefix,
@@ -154,18 +148,30 @@ func (d *DenodoConnector) ReflectLocalColumnAttributeToDenodo(columnAssets map[s
 				}
 				err = d.DenodoRepo.UpdateLocalViewFieldDescription(updateLocalViewColumnInput)
 				if err != nil {
-					code, denodoErr := rest.GetErrorCode(err)
-					if denodoErr != nil {
+					if code.Decide(err) != code.Skip {
+						return err
+					}
+					d.Logger.Warning("Update field description failed due to the %s error. Skip update. database name: %s. table name: %s column name: %s", code.CategoryOf(err), qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
+				}
+				d.Logger.Debug("Updated column description. database name: %s. table name: %s column name: %s", qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
+			}
+			if !d.TagMapping.IsEmpty() && localViewColumn.InLocal {
+				err = d.syncLocalViewFieldTags(qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn, action, columnAsset)
+				if err != nil {
+					if code.Decide(err) != code.Skip {
 						return err
 					}
-					switch code {
-					case 401, 403:
-						d.Logger.Warning("Update field description failed due to the ErrorCode %v Skip update. database name: %s. table name: %s column name: %s", code, qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
-					default:
+					d.Logger.Warning("Update field tags failed due to the %s error. Skip update. database name: %s. table name: %s column name: %s", code.CategoryOf(err), qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
+				}
+			}
+			if localViewColumn.InLocal {
+				err = d.syncLocalViewFieldLogicalName(qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn, action, columnAsset)
+				if err != nil {
+					if code.Decide(err) != code.Skip {
 						return err
 					}
+					d.Logger.Warning("Update field logical name failed due to the %s error. Skip update. database name: %s. table name: %s column name: %s", code.CategoryOf(err), qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
 				}
-				d.Logger.Debug("Updated column description. database name: %s. table name: %s column name: %s", qdcDatabaseAsset.Name, qdcTableAsset.Name, localViewColumn.Name)
 			}
 		}
 	}
@@ -181,7 +187,7 @@ func shouldUpdateDenodoLocalDatabase(prefixForUpdate, overwriteMode string, db m
 		return true
 	}
 
-	if strings.HasPrefix(db.DatabaseDescription, prefixForUpdate) && qdcDatabase.Description != "" {
+	if utils.HasPrefixInFormat(db.DatabaseDescription, prefixForUpdate, utils.FormatRichText) && qdcDatabase.Description != "" {
 		return true
 	}
 
@@ -200,7 +206,7 @@ func shouldUpdateDenodoLocalTable(prefixForUpdate, overwriteMode string, view mo
 		return true
 	}
 
-	if strings.HasPrefix(view.Description, prefixForUpdate) && qdcTable.Description != "" {
+	if utils.HasPrefixInFormat(view.Description, prefixForUpdate, utils.FormatRichText) && qdcTable.Description != "" {
 		return true
 	}
 
@@ -219,7 +225,7 @@ func shouldUpdateDenodoLocalColumn(prefixForUpdate, overwriteMode string, viewCo
 		return true
 	}
 
-	if strings.HasPrefix(viewColumn.Description, prefixForUpdate) && qdcColumn.Description != "" {
+	if utils.HasPrefixInFormat(viewColumn.Description, prefixForUpdate, utils.FormatRichText) && qdcColumn.Description != "" {
 		return true
 	}
 
@@ -0,0 +1,85 @@
+package denodo
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/denodo/rest/models"
+	"quollio-reverse-agent/repository/qdc"
+)
+
+// parseLogicalNameMode returns the overwrite mode of the native logical names. Empty string disables the update.
+func parseLogicalNameMode(mode string) (string, error) {
+	switch mode {
+	case "", utils.OverwriteIfEmpty, utils.OverwriteAll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid logical name mode %s. It must be %s or %s", mode, utils.OverwriteIfEmpty, utils.OverwriteAll)
+	}
+}
+
+// shouldUpdateLocalLogicalName returns true when the logical name of the Data Catalog element should be replaced.
+// MEMO: Logical names don't have the prefix, so OVERWRITE_IF_EMPTY updates only empty logical names.
+func shouldUpdateLocalLogicalName(mode, action, currentLogicalName string, qdcAsset qdc.Data) bool {
+	if mode == "" || action == utils.AssetStateSkip || action == utils.AssetStateClear {
+		return false
+	}
+	if qdcAsset.LogicalName == "" || qdcAsset.LogicalName == currentLogicalName {
+		return false
+	}
+	if mode == utils.OverwriteAll {
+		return true
+	}
+	return currentLogicalName == ""
+}
+
+// shouldSyncLogicalName returns true when the logical name of the asset may be written into the native field.
+func (d *DenodoConnector) shouldSyncLogicalName(qdcAsset qdc.Data) bool {
+	return d.LogicalNameMode != "" && qdcAsset.LogicalName != ""
+}
+
+// isLogicalNameInDescription returns true when the logical name has to be written into the description of the field.
+// The Data Catalog descriptions don't contain the logical name when it's written into the native field.
+func (d *DenodoConnector) isLogicalNameInDescription(field string) bool {
+	if d.LogicalNameMode == "" {
+		return true
+	}
+	switch field {
+	case utils.FieldDenodoDataCatalogViewDescription, utils.FieldDenodoDataCatalogFieldDescription:
+		return false
+	default:
+		return true
+	}
+}
+
+func (d *DenodoConnector) syncLocalViewLogicalName(viewDetail models.ViewDetail, action string, tableAsset qdc.Data) error {
+	if !shouldUpdateLocalLogicalName(d.LogicalNameMode, action, viewDetail.LogicalName, tableAsset) {
+		return nil
+	}
+	err := d.DenodoRepo.UpdateLocalViewLogicalName(models.UpdateLocalViewLogicalNameInput{
+		ID:          viewDetail.Id,
+		LogicalName: tableAsset.LogicalName,
+	})
+	if err != nil {
+		return err
+	}
+	d.Report.Add(report.INFO, "denodo", fmt.Sprintf("%s.%s", viewDetail.DatabaseName, viewDetail.Name), "logical_name", fmt.Sprintf("Updated from %q to %q.", viewDetail.LogicalName, tableAsset.LogicalName))
+	return nil
+}
+
+func (d *DenodoConnector) syncLocalViewFieldLogicalName(databaseName, viewName string, viewColumn models.ViewColumn, action string, columnAsset qdc.Data) error {
+	if !shouldUpdateLocalLogicalName(d.LogicalNameMode, action, viewColumn.LogicalName, columnAsset) {
+		return nil
+	}
+	err := d.DenodoRepo.UpdateLocalViewFieldLogicalName(models.UpdateLocalViewFieldLogicalNameInput{
+		DatabaseName:     databaseName,
+		FieldLogicalName: columnAsset.LogicalName,
+		FieldName:        viewColumn.Name,
+		ViewName:         viewName,
+	})
+	if err != nil {
+		return err
+	}
+	d.Report.Add(report.INFO, "denodo", fmt.Sprintf("%s.%s.%s", databaseName, viewName, viewColumn.Name), "logical_name", fmt.Sprintf("Updated from %q to %q.", viewColumn.LogicalName, columnAsset.LogicalName))
+	return nil
+}
@@ -0,0 +1,61 @@
+package denodo
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+)
+
+func TestShouldUpdateLocalLogicalName(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mode        string
+		action      string
+		current     string
+		logicalName string
+		want        bool
+	}{
+		{name: "disabled", mode: "", action: utils.AssetStateUpdate, current: "", logicalName: "顧客", want: false},
+		{name: "empty logical name in qdc", mode: utils.OverwriteAll, action: utils.AssetStateUpdate, current: "old", logicalName: "", want: false},
+		{name: "same logical name", mode: utils.OverwriteAll, action: utils.AssetStateUpdate, current: "顧客", logicalName: "顧客", want: false},
+		{name: "if empty with current value", mode: utils.OverwriteIfEmpty, action: utils.AssetStateUpdate, current: "old", logicalName: "顧客", want: false},
+		{name: "if empty without current value", mode: utils.OverwriteIfEmpty, action: utils.AssetStateUpdate, current: "", logicalName: "顧客", want: true},
+		{name: "overwrite all", mode: utils.OverwriteAll, action: utils.AssetStateUpdate, current: "old", logicalName: "顧客", want: true},
+		{name: "deprecate", mode: utils.OverwriteAll, action: utils.AssetStateDeprecate, current: "old", logicalName: "顧客", want: true},
+		{name: "skip", mode: utils.OverwriteAll, action: utils.AssetStateSkip, current: "", logicalName: "顧客", want: false},
+		{name: "clear", mode: utils.OverwriteAll, action: utils.AssetStateClear, current: "", logicalName: "顧客", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := shouldUpdateLocalLogicalName(tc.mode, tc.action, tc.current, qdc.Data{LogicalName: tc.logicalName})
+			if got != tc.want {
+				t.Errorf("want %v but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseLogicalNameMode(t *testing.T) {
+	for _, mode := range []string{"", utils.OverwriteIfEmpty, utils.OverwriteAll} {
+		if _, err := parseLogicalNameMode(mode); err != nil {
+			t.Errorf("unexpected error for %q: %s", mode, err)
+		}
+	}
+	if _, err := parseLogicalNameMode("INVALID"); err == nil {
+		t.Errorf("expected an error for an invalid mode")
+	}
+}
+
+func TestIsLogicalNameInDescription(t *testing.T) {
+	disabled := DenodoConnector{}
+	if !disabled.isLogicalNameInDescription(utils.FieldDenodoDataCatalogViewDescription) {
+		t.Errorf("the logical name should be in the description when the mode is disabled")
+	}
+	enabled := DenodoConnector{LogicalNameMode: utils.OverwriteIfEmpty}
+	if enabled.isLogicalNameInDescription(utils.FieldDenodoDataCatalogFieldDescription) {
+		t.Errorf("the logical name should not be in the Data Catalog description when the mode is enabled")
+	}
+	if !enabled.isLogicalNameInDescription(utils.FieldDenodoDataCatalogDatabaseDescription) {
+		t.Errorf("the logical name should be in the database description because it has no native field")
+	}
+}
@@ -0,0 +1,397 @@
+package denodo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/denodo/rest/models"
+	"quollio-reverse-agent/repository/qdc"
+	"sort"
+	"strings"
+)
+
+// TagMapping maps QDIC tags to the tags, categories and custom properties of the Denodo Data Catalog.
+// Only the tags, categories and custom properties in the mapping are owned by the agent. The others are never changed.
+type TagMapping struct {
+	Mappings []TagMappingRule `json:"mappings"`
+}
+
+// TagMappingRule assigns the Denodo tag, category and custom property value to the assets that have the selected QDIC tag.
+// All of the matched rules are applied. Categories and custom properties are assigned only to views.
+// The values of the matched rules for the same custom property are joined with commas.
+type TagMappingRule struct {
+	qdc.TagSelector
+	Tag           string `json:"tag"`
+	Category      string `json:"category"`
+	PropertyGroup string `json:"property_group"`
+	Property      string `json:"property"`
+	Value         string `json:"value"`
+}
+
+// PropertyRef is the custom property in the property group. The custom properties are identified by their names.
+type PropertyRef struct {
+	Group string
+	Name  string
+}
+
+func LoadTagMapping(path string) (TagMapping, error) {
+	if path == "" {
+		return TagMapping{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return TagMapping{}, err
+	}
+	var mapping TagMapping
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return TagMapping{}, fmt.Errorf("invalid tag mapping %s: %s", path, err)
+	}
+	propertyGroups := make(map[string]string)
+	for i, rule := range mapping.Mappings {
+		if rule.TagSelector.IsEmpty() {
+			return TagMapping{}, fmt.Errorf("mapping %d has none of tag_group_id, tag_id, tag_group_name and tag_name", i)
+		}
+		if rule.Tag == "" && rule.Category == "" && rule.Property == "" {
+			return TagMapping{}, fmt.Errorf("mapping %d has none of tag, category and property", i)
+		}
+		if rule.Property == "" {
+			continue
+		}
+		if rule.PropertyGroup == "" || rule.Value == "" {
+			return TagMapping{}, fmt.Errorf("mapping %d needs property_group and value for the property", i)
+		}
+		if group, ok := propertyGroups[rule.Property]; ok && group != rule.PropertyGroup {
+			return TagMapping{}, fmt.Errorf("mapping %d has the property %s in both of %s and %s", i, rule.Property, group, rule.PropertyGroup)
+		}
+		propertyGroups[rule.Property] = rule.PropertyGroup
+	}
+	return mapping, nil
+}
+
+func (m TagMapping) IsEmpty() bool {
+	return len(m.Mappings) == 0
+}
+
+// TagSelectors returns the selectors of the rules, so that the tag names in them are resolved.
+func (m *TagMapping) TagSelectors() []*qdc.TagSelector {
+	selectors := make([]*qdc.TagSelector, 0, len(m.Mappings))
+	for i := range m.Mappings {
+		selectors = append(selectors, &m.Mappings[i].TagSelector)
+	}
+	return selectors
+}
+
+// TagNames returns the names of the Denodo tags in the mapping without duplication.
+func (m TagMapping) TagNames() []string {
+	var names []string
+	for _, rule := range m.Mappings {
+		names = appendIfMissing(names, rule.Tag)
+	}
+	return names
+}
+
+// CategoryNames returns the names of the Denodo categories in the mapping without duplication.
+func (m TagMapping) CategoryNames() []string {
+	var names []string
+	for _, rule := range m.Mappings {
+		names = appendIfMissing(names, rule.Category)
+	}
+	return names
+}
+
+// PropertyRefs returns the custom properties in the mapping without duplication.
+func (m TagMapping) PropertyRefs() []PropertyRef {
+	var refs []PropertyRef
+	seen := make(map[string]bool)
+	for _, rule := range m.Mappings {
+		if rule.Property == "" || seen[rule.Property] {
+			continue
+		}
+		seen[rule.Property] = true
+		refs = append(refs, PropertyRef{Group: rule.PropertyGroup, Name: rule.Property})
+	}
+	return refs
+}
+
+// GenPropertyValues returns the values of the custom properties for the asset by the property names.
+func (m TagMapping) GenPropertyValues(asset qdc.Data) map[string]string {
+	valuesByProperty := make(map[string][]string)
+	for _, rule := range m.Mappings {
+		if rule.Property == "" || !rule.Matches(asset) {
+			continue
+		}
+		valuesByProperty[rule.Property] = appendIfMissing(valuesByProperty[rule.Property], rule.Value)
+	}
+	propertyValues := make(map[string]string)
+	for property, values := range valuesByProperty {
+		propertyValues[property] = strings.Join(values, ", ")
+	}
+	return propertyValues
+}
+
+// GenNames returns the names of the Denodo tags and categories for the asset.
+func (m TagMapping) GenNames(asset qdc.Data) (tagNames, categoryNames []string) {
+	for _, rule := range m.Mappings {
+		if !rule.Matches(asset) {
+			continue
+		}
+		tagNames = appendIfMissing(tagNames, rule.Tag)
+		categoryNames = appendIfMissing(categoryNames, rule.Category)
+	}
+	return tagNames, categoryNames
+}
+
+func appendIfMissing(names []string, name string) []string {
+	if name == "" {
+		return names
+	}
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// genAssignedIDs returns the IDs that should be assigned to the element.
+// The current IDs that are not managed are kept, and the managed IDs are replaced with the desired IDs.
+// The second return value is false when the assignment doesn't have to be changed.
+func genAssignedIDs(currentIDs, desiredIDs []int, managedIDs map[int]bool) ([]int, bool) {
+	current := make(map[int]bool)
+	assigned := make(map[int]bool)
+	for _, id := range currentIDs {
+		current[id] = true
+		if !managedIDs[id] {
+			assigned[id] = true
+		}
+	}
+	for _, id := range desiredIDs {
+		assigned[id] = true
+	}
+	ids := make([]int, 0, len(assigned))
+	for id := range assigned {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	if len(ids) != len(current) {
+		return ids, true
+	}
+	for _, id := range ids {
+		if !current[id] {
+			return ids, true
+		}
+	}
+	return ids, false
+}
+
+// genPropertyValues returns the values of the managed custom properties that should be set to the view.
+// The managed custom properties that aren't desired are cleared. The current values are keyed by the property names.
+// The second return value is false when none of the values has to be changed.
+func genPropertyValues(currentValues map[string]interface{}, desiredValues map[string]string, managedPropertyIDs map[string]int) ([]models.PropertyValue, bool) {
+	names := make([]string, 0, len(managedPropertyIDs))
+	for name := range managedPropertyIDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var values []models.PropertyValue
+	shouldBeUpdated := false
+	for _, name := range names {
+		var currentValue string
+		if value, ok := currentValues[name]; ok && value != nil {
+			currentValue = fmt.Sprint(value)
+		}
+		if currentValue != desiredValues[name] {
+			shouldBeUpdated = true
+		}
+		values = append(values, models.PropertyValue{PropertyID: managedPropertyIDs[name], Value: desiredValues[name]})
+	}
+	return values, shouldBeUpdated
+}
+
+// resolveDataCatalogTags looks up the IDs of the tags, categories and custom properties in the mapping. Missing ones are created.
+// The property groups are not created, so the custom properties in the missing groups are ignored.
+func (d *DenodoConnector) resolveDataCatalogTags() error {
+	tags, err := d.DenodoRepo.GetTags()
+	if err != nil {
+		return err
+	}
+	d.denodoTagIDs = make(map[string]int)
+	for _, tag := range tags {
+		d.denodoTagIDs[tag.Name] = tag.ID
+	}
+	for _, name := range d.TagMapping.TagNames() {
+		if _, ok := d.denodoTagIDs[name]; ok {
+			continue
+		}
+		tag, err := d.DenodoRepo.CreateTag(models.TagInput{Name: name})
+		if err != nil {
+			return err
+		}
+		d.denodoTagIDs[name] = tag.ID
+		d.Report.Add(report.INFO, "denodo", name, "tag", "Created tag.")
+	}
+
+	categories, err := d.DenodoRepo.GetCategories()
+	if err != nil {
+		return err
+	}
+	d.denodoCategoryIDs = make(map[string]int)
+	for _, category := range categories {
+		d.denodoCategoryIDs[category.Name] = category.ID
+	}
+	for _, name := range d.TagMapping.CategoryNames() {
+		if _, ok := d.denodoCategoryIDs[name]; ok {
+			continue
+		}
+		category, err := d.DenodoRepo.CreateCategory(models.CategoryInput{Name: name})
+		if err != nil {
+			return err
+		}
+		d.denodoCategoryIDs[name] = category.ID
+		d.Report.Add(report.INFO, "denodo", name, "category", "Created category.")
+	}
+
+	d.denodoPropertyIDs = make(map[string]int)
+	propertyRefs := d.TagMapping.PropertyRefs()
+	if len(propertyRefs) == 0 {
+		return nil
+	}
+	propertyGroups, err := d.DenodoRepo.GetPropertyGroups()
+	if err != nil {
+		return err
+	}
+	propertyGroupsByName := make(map[string]models.PropertyGroup)
+	for _, propertyGroup := range propertyGroups {
+		propertyGroupsByName[propertyGroup.Name] = propertyGroup
+	}
+	for _, ref := range propertyRefs {
+		propertyGroup, ok := propertyGroupsByName[ref.Group]
+		if !ok {
+			d.Logger.Warning("Property group %s is not found. The mapping to %s is ignored.", ref.Group, ref.Name)
+			d.Report.Add(report.WARNING, "denodo", ref.Group, "property", "Property group is not found.")
+			continue
+		}
+		for _, property := range propertyGroup.Properties {
+			if property.Name == ref.Name {
+				d.denodoPropertyIDs[ref.Name] = property.ID
+				break
+			}
+		}
+		if _, ok := d.denodoPropertyIDs[ref.Name]; ok {
+			continue
+		}
+		property, err := d.DenodoRepo.CreateProperty(propertyGroup.ID, models.PropertyInput{Name: ref.Name, Type: "TEXT"})
+		if err != nil {
+			return err
+		}
+		d.denodoPropertyIDs[ref.Name] = property.ID
+		d.Report.Add(report.INFO, "denodo", fmt.Sprintf("%s.%s", ref.Group, ref.Name), "property", "Created custom property.")
+	}
+	return nil
+}
+
+func lookupIDs(names []string, idsByName map[string]int) []int {
+	var ids []int
+	for _, name := range names {
+		if id, ok := idsByName[name]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// genDesiredNames returns the names of the tags and categories for the asset. Nothing is assigned to the assets with CLEAR action.
+func (d *DenodoConnector) genDesiredNames(action string, asset qdc.Data) (tagNames, categoryNames []string) {
+	if action == utils.AssetStateClear {
+		return nil, nil
+	}
+	return d.TagMapping.GenNames(asset)
+}
+
+// genDesiredPropertyValues returns the values of the custom properties for the asset. The values are cleared for the assets with CLEAR action.
+func (d *DenodoConnector) genDesiredPropertyValues(action string, asset qdc.Data) map[string]string {
+	if action == utils.AssetStateClear {
+		return map[string]string{}
+	}
+	return d.TagMapping.GenPropertyValues(asset)
+}
+
+func (d *DenodoConnector) managedTagIDs() map[int]bool {
+	managed := make(map[int]bool)
+	for _, id := range lookupIDs(d.TagMapping.TagNames(), d.denodoTagIDs) {
+		managed[id] = true
+	}
+	return managed
+}
+
+func (d *DenodoConnector) managedCategoryIDs() map[int]bool {
+	managed := make(map[int]bool)
+	for _, id := range lookupIDs(d.TagMapping.CategoryNames(), d.denodoCategoryIDs) {
+		managed[id] = true
+	}
+	return managed
+}
+
+// syncLocalViewTags assigns the mapped tags, categories and custom property values to the view.
+func (d *DenodoConnector) syncLocalViewTags(viewDetail models.ViewDetail, action string, tableAsset qdc.Data) error {
+	target := fmt.Sprintf("%s.%s", viewDetail.DatabaseName, viewDetail.Name)
+	desiredTagNames, desiredCategoryNames := d.genDesiredNames(action, tableAsset)
+	qdcTagNames := d.TagDictionary.TagNames(tableAsset)
+
+	var currentTagIDs []int
+	for _, tag := range viewDetail.Tags {
+		currentTagIDs = append(currentTagIDs, tag.ID)
+	}
+	if tagIDs, ok := genAssignedIDs(currentTagIDs, lookupIDs(desiredTagNames, d.denodoTagIDs), d.managedTagIDs()); ok {
+		if err := d.DenodoRepo.UpdateLocalViewTags(viewDetail.Id, tagIDs); err != nil {
+			return err
+		}
+		d.Report.Add(report.INFO, "denodo", target, "tag", fmt.Sprintf("Assigned tags %v. QDIC tags %v.", desiredTagNames, qdcTagNames))
+	}
+
+	var currentCategoryIDs []int
+	for _, category := range viewDetail.Categories {
+		currentCategoryIDs = append(currentCategoryIDs, category.ID)
+	}
+	if categoryIDs, ok := genAssignedIDs(currentCategoryIDs, lookupIDs(desiredCategoryNames, d.denodoCategoryIDs), d.managedCategoryIDs()); ok {
+		if err := d.DenodoRepo.UpdateLocalViewCategories(viewDetail.Id, categoryIDs); err != nil {
+			return err
+		}
+		d.Report.Add(report.INFO, "denodo", target, "category", fmt.Sprintf("Assigned categories %v. QDIC tags %v.", desiredCategoryNames, qdcTagNames))
+	}
+
+	desiredPropertyValues := d.genDesiredPropertyValues(action, tableAsset)
+	if propertyValues, ok := genPropertyValues(viewDetail.PropertyInfo.CustomTabPropertyMap, desiredPropertyValues, d.denodoPropertyIDs); ok {
+		if err := d.DenodoRepo.UpdateLocalViewPropertyValues(viewDetail.Id, propertyValues); err != nil {
+			return err
+		}
+		d.Report.Add(report.INFO, "denodo", target, "property", fmt.Sprintf("Set custom properties %v. QDIC tags %v.", desiredPropertyValues, qdcTagNames))
+	}
+	return nil
+}
+
+// syncLocalViewFieldTags assigns the mapped tags to the field.
+func (d *DenodoConnector) syncLocalViewFieldTags(databaseName, viewName string, viewColumn models.ViewColumn, action string, columnAsset qdc.Data) error {
+	desiredTagNames, _ := d.genDesiredNames(action, columnAsset)
+	var currentTagIDs []int
+	for _, tag := range viewColumn.Tags {
+		currentTagIDs = append(currentTagIDs, tag.ID)
+	}
+	tagIDs, ok := genAssignedIDs(currentTagIDs, lookupIDs(desiredTagNames, d.denodoTagIDs), d.managedTagIDs())
+	if !ok {
+		return nil
+	}
+	err := d.DenodoRepo.UpdateLocalViewFieldTags(models.UpdateLocalViewFieldTagsInput{
+		DatabaseName: databaseName,
+		ViewName:     viewName,
+		FieldName:    viewColumn.Name,
+		TagIDs:       tagIDs,
+	})
+	if err != nil {
+		return err
+	}
+	d.Report.Add(report.INFO, "denodo", fmt.Sprintf("%s.%s.%s", databaseName, viewName, viewColumn.Name), "tag", fmt.Sprintf("Assigned tags %v. QDIC tags %v.", desiredTagNames, d.TagDictionary.TagNames(columnAsset)))
+	return nil
+}
@@ -0,0 +1,117 @@
+package denodo
+
+import (
+	"quollio-reverse-agent/repository/denodo/rest/models"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTagMappingGenNames(t *testing.T) {
+	mapping := TagMapping{
+		Mappings: []TagMappingRule{
+			{TagSelector: qdc.TagSelector{TagID: "tag-pii"}, Tag: "PII", Category: "Sensitive"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, Tag: "PII"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-2"}, Category: "Sales"},
+		},
+	}
+	asset := qdc.Data{ManualTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-pii"}}}
+	tagNames, categoryNames := mapping.GenNames(asset)
+	if diff := cmp.Diff([]string{"PII"}, tagNames); diff != "" {
+		t.Errorf("tagNames mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Sensitive"}, categoryNames); diff != "" {
+		t.Errorf("categoryNames mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Sensitive", "Sales"}, mapping.CategoryNames()); diff != "" {
+		t.Errorf("CategoryNames mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTagMappingGenPropertyValues(t *testing.T) {
+	mapping := TagMapping{
+		Mappings: []TagMappingRule{
+			{TagSelector: qdc.TagSelector{TagID: "tag-pii"}, PropertyGroup: "Governance", Property: "Sensitivity", Value: "PII"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, PropertyGroup: "Governance", Property: "Sensitivity", Value: "Internal"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, PropertyGroup: "Governance", Property: "Domain", Value: "Sales"},
+			{TagSelector: qdc.TagSelector{TagGroupID: "tggr-2"}, PropertyGroup: "Governance", Property: "Domain", Value: "Finance"},
+		},
+	}
+	asset := qdc.Data{ManualTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-pii"}}}
+	want := map[string]string{"Sensitivity": "PII, Internal", "Domain": "Sales"}
+	if diff := cmp.Diff(want, mapping.GenPropertyValues(asset)); diff != "" {
+		t.Errorf("property values mismatch (-want +got):\n%s", diff)
+	}
+	wantRefs := []PropertyRef{{Group: "Governance", Name: "Sensitivity"}, {Group: "Governance", Name: "Domain"}}
+	if diff := cmp.Diff(wantRefs, mapping.PropertyRefs()); diff != "" {
+		t.Errorf("PropertyRefs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenPropertyValues(t *testing.T) {
+	managed := map[string]int{"Domain": 1, "Sensitivity": 2}
+	testCases := []struct {
+		name       string
+		current    map[string]interface{}
+		desired    map[string]string
+		wantValues []models.PropertyValue
+		wantUpdate bool
+	}{
+		{
+			name:       "no change",
+			current:    map[string]interface{}{"Domain": "Sales", "Sensitivity": nil, "Owner": "alice"},
+			desired:    map[string]string{"Domain": "Sales"},
+			wantValues: []models.PropertyValue{{PropertyID: 1, Value: "Sales"}, {PropertyID: 2, Value: ""}},
+			wantUpdate: false,
+		},
+		{
+			name:       "set",
+			current:    map[string]interface{}{},
+			desired:    map[string]string{"Sensitivity": "PII"},
+			wantValues: []models.PropertyValue{{PropertyID: 1, Value: ""}, {PropertyID: 2, Value: "PII"}},
+			wantUpdate: true,
+		},
+		{
+			name:       "clear",
+			current:    map[string]interface{}{"Domain": "Sales"},
+			desired:    map[string]string{},
+			wantValues: []models.PropertyValue{{PropertyID: 1, Value: ""}, {PropertyID: 2, Value: ""}},
+			wantUpdate: true,
+		},
+	}
+	for _, tt := range testCases {
+		values, shouldBeUpdated := genPropertyValues(tt.current, tt.desired, managed)
+		if shouldBeUpdated != tt.wantUpdate {
+			t.Errorf("%s: want %v but got %v", tt.name, tt.wantUpdate, shouldBeUpdated)
+		}
+		if diff := cmp.Diff(tt.wantValues, values); diff != "" {
+			t.Errorf("%s: values mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
+
+func TestGenAssignedIDs(t *testing.T) {
+	managed := map[int]bool{1: true, 2: true}
+	testCases := []struct {
+		name       string
+		current    []int
+		desired    []int
+		wantIDs    []int
+		wantUpdate bool
+	}{
+		{name: "no change", current: []int{1, 9}, desired: []int{1}, wantIDs: []int{1, 9}, wantUpdate: false},
+		{name: "add", current: []int{9}, desired: []int{2}, wantIDs: []int{2, 9}, wantUpdate: true},
+		{name: "replace", current: []int{1, 9}, desired: []int{2}, wantIDs: []int{2, 9}, wantUpdate: true},
+		{name: "clear", current: []int{1, 2, 9}, desired: nil, wantIDs: []int{9}, wantUpdate: true},
+	}
+	for _, tt := range testCases {
+		ids, shouldBeUpdated := genAssignedIDs(tt.current, tt.desired, managed)
+		if shouldBeUpdated != tt.wantUpdate {
+			t.Errorf("%s: want %v but got %v", tt.name, tt.wantUpdate, shouldBeUpdated)
+		}
+		if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+			t.Errorf("%s: ids mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
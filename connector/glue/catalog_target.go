@@ -0,0 +1,136 @@
+package glue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/repository/glue"
+	"quollio-reverse-agent/repository/lakeformation"
+	"quollio-reverse-agent/repository/qdc"
+	"slices"
+	"strings"
+)
+
+// CatalogTarget is a Glue Data Catalog to be synced. The root assets of QDIC are matched to the catalog
+// by RootAssets, or by the account ID and the region in the names of the root asset and its path.
+type CatalogTarget struct {
+	AccountID  string   `json:"account_id"`
+	Region     string   `json:"region"`
+	RoleARN    string   `json:"role_arn"`
+	RootAssets []string `json:"root_assets"`
+
+	glueRepo          glue.GlueClient
+	lakeFormationRepo lakeformation.LakeFormationClient
+	targetGlueRepos   map[GlueRepoKey]glue.GlueClient
+}
+
+type CatalogTargets struct {
+	Targets []CatalogTarget `json:"targets"`
+}
+
+// LoadCatalogTargets loads the targets from the JSON file. The region is defaultRegion if it's omitted.
+func LoadCatalogTargets(path, defaultRegion string) (CatalogTargets, error) {
+	if path == "" {
+		return CatalogTargets{}, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return CatalogTargets{}, err
+	}
+	var targets CatalogTargets
+	if err := json.Unmarshal(b, &targets); err != nil {
+		return CatalogTargets{}, err
+	}
+	for i, target := range targets.Targets {
+		if target.AccountID == "" || target.RoleARN == "" {
+			return CatalogTargets{}, fmt.Errorf("account_id and role_arn are required for the target %d", i)
+		}
+		if target.Region == "" {
+			targets.Targets[i].Region = defaultRegion
+		}
+	}
+	return targets, nil
+}
+
+func (c CatalogTarget) String() string {
+	return fmt.Sprintf("%s/%s", c.AccountID, c.Region)
+}
+
+func (c CatalogTarget) isExplicitlyMatched(rootAsset qdc.Data) bool {
+	return slices.Contains(c.RootAssets, rootAsset.ID) || slices.Contains(c.RootAssets, rootAsset.PhysicalName)
+}
+
+func genRootAssetNames(rootAsset qdc.Data) []string {
+	names := []string{rootAsset.PhysicalName}
+	for _, path := range rootAsset.Path {
+		names = append(names, path.Name)
+	}
+	return names
+}
+
+func containsInNames(names []string, value string) bool {
+	for _, name := range names {
+		if value != "" && strings.Contains(name, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRootAssets returns the root assets of each target in the order of the targets, and the root assets that
+// don't match any target. All of the root assets belong to the target if there is only one target.
+func MatchRootAssets(targets []CatalogTarget, rootAssets []qdc.Data) ([][]qdc.Data, []qdc.Data) {
+	matched := make([][]qdc.Data, len(targets))
+	var unmatched []qdc.Data
+	for _, rootAsset := range rootAssets {
+		index := matchRootAsset(targets, rootAsset)
+		if index < 0 {
+			unmatched = append(unmatched, rootAsset)
+			continue
+		}
+		matched[index] = append(matched[index], rootAsset)
+	}
+	return matched, unmatched
+}
+
+func matchRootAsset(targets []CatalogTarget, rootAsset qdc.Data) int {
+	if len(targets) == 1 {
+		return 0
+	}
+	for i, target := range targets {
+		if target.isExplicitlyMatched(rootAsset) {
+			return i
+		}
+	}
+	names := genRootAssetNames(rootAsset)
+	var candidates []int
+	for i, target := range targets {
+		if len(target.RootAssets) == 0 && containsInNames(names, target.AccountID) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	// MEMO: The region distinguishes the catalogs of the same account.
+	var regionalCandidates []int
+	for _, i := range candidates {
+		if containsInNames(names, targets[i].Region) {
+			regionalCandidates = append(regionalCandidates, i)
+		}
+	}
+	if len(regionalCandidates) == 1 {
+		return regionalCandidates[0]
+	}
+	return -1
+}
+
+// withCatalogTarget returns a copy of the connector that syncs the catalog of the target.
+func (g *GlueConnector) withCatalogTarget(target CatalogTarget) *GlueConnector {
+	catalogConnector := *g
+	catalogConnector.GlueRepo = target.glueRepo
+	catalogConnector.LakeFormationRepo = target.lakeFormationRepo
+	catalogConnector.TargetGlueRepos = target.targetGlueRepos
+	catalogConnector.AthenaAccountID = target.AccountID
+	return &catalogConnector
+}
@@ -0,0 +1,90 @@
+package glue
+
+import (
+	"os"
+	"path/filepath"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLoadCatalogTargets(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name    string
+		content string
+		want    []CatalogTarget
+		wantErr bool
+	}{
+		{
+			name:    "default region",
+			content: `{"targets": [{"account_id": "111111111111", "role_arn": "arn:aws:iam::111111111111:role/a"}, {"account_id": "111111111111", "region": "eu-west-1", "role_arn": "arn:aws:iam::111111111111:role/a", "root_assets": ["athena-eu"]}]}`,
+			want: []CatalogTarget{
+				{AccountID: "111111111111", Region: "us-east-1", RoleARN: "arn:aws:iam::111111111111:role/a"},
+				{AccountID: "111111111111", Region: "eu-west-1", RoleARN: "arn:aws:iam::111111111111:role/a", RootAssets: []string{"athena-eu"}},
+			},
+		},
+		{
+			name:    "missing role",
+			content: `{"targets": [{"account_id": "111111111111"}]}`,
+			wantErr: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i))+".json")
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadCatalogTargets(path, "us-east-1")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got.Targets, cmpopts.IgnoreUnexported(CatalogTarget{})); diff != "" {
+				t.Errorf("targets mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatchRootAssets(t *testing.T) {
+	targets := []CatalogTarget{
+		{AccountID: "111111111111", Region: "us-east-1"},
+		{AccountID: "111111111111", Region: "eu-west-1"},
+		{AccountID: "222222222222", Region: "us-east-1"},
+		{AccountID: "333333333333", Region: "us-east-1", RootAssets: []string{"scm-explicit"}},
+	}
+	rootAssets := []qdc.Data{
+		{ID: "scm-1", PhysicalName: "awsdatacatalog", Path: []qdc.Path{{PathLayer: "schema1", Name: "111111111111-us-east-1"}}},
+		{ID: "scm-2", PhysicalName: "awsdatacatalog", Path: []qdc.Path{{PathLayer: "schema1", Name: "111111111111-eu-west-1"}}},
+		{ID: "scm-3", PhysicalName: "222222222222"},
+		{ID: "scm-explicit", PhysicalName: "awsdatacatalog"},
+		{ID: "scm-4", PhysicalName: "111111111111"},
+		{ID: "scm-5", PhysicalName: "444444444444"},
+	}
+	matched, unmatched := MatchRootAssets(targets, rootAssets)
+	ids := func(assets []qdc.Data) []string {
+		var ids []string
+		for _, asset := range assets {
+			ids = append(ids, asset.ID)
+		}
+		return ids
+	}
+	want := [][]string{{"scm-1"}, {"scm-2"}, {"scm-3"}, {"scm-explicit"}}
+	for i := range targets {
+		if diff := cmp.Diff(want[i], ids(matched[i])); diff != "" {
+			t.Errorf("target %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+	// MEMO: scm-4 matches two catalogs of the same account, and scm-5 matches none.
+	if diff := cmp.Diff([]string{"scm-4", "scm-5"}, ids(unmatched)); diff != "" {
+		t.Errorf("unmatched mismatch (-want +got):\n%s", diff)
+	}
+
+	matched, unmatched = MatchRootAssets(targets[:1], rootAssets)
+	if len(matched[0]) != len(rootAssets) || len(unmatched) != 0 {
+		t.Errorf("all of the root assets should match the only target")
+	}
+}
@@ -1,56 +1,158 @@
 package glue
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"quollio-reverse-agent/common/code"
 	"quollio-reverse-agent/common/logger"
+	"quollio-reverse-agent/common/report"
 	"quollio-reverse-agent/common/utils"
 	"quollio-reverse-agent/repository/glue"
-	"quollio-reverse-agent/repository/glue/code"
+	"quollio-reverse-agent/repository/lakeformation"
 	"quollio-reverse-agent/repository/qdc"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
 	"github.com/aws/aws-sdk-go-v2/service/glue/types"
 )
 
 type GlueConnector struct {
-	QDCExternalAPIClient qdc.QDCExternalAPI
-	GlueRepo             glue.GlueClient
-	AssetCreatedBy       string
-	AthenaAccountID      string
-	OverwriteMode        string
-	PrefixForUpdate      string
-	Logger               *logger.BuiltinLogger
+	QDCExternalAPIClient        qdc.QDCExternalAPI
+	GlueRepo                    glue.GlueClient
+	LakeFormationRepo           lakeformation.LakeFormationClient
+	AssetCreatedBy              string
+	AssetFilter                 qdc.AssetFilter
+	AssetStatePolicy            utils.AssetStatePolicy
+	AthenaAccountID             string
+	BulkReadThreshold           int
+	CatalogTargets              []CatalogTarget
+	CommentParameterSyncEnabled bool
+	DeprecationParameterKey     string
+	DescriptionLimiter          utils.DescriptionLimiter
+	LFTagMapping                LFTagMapping
+	TagDictionary               qdc.TagDictionary
+	MetadataParameterKeys       MetadataParameterKeys
+	OverwriteMode               string
+	PrefixForUpdate             string
+	ResourceLinkPolicy          string
+	SkipArchiveOnMetadataUpdate bool
+	SkipUnchangedTableUpdate    bool
+	TableVersionRetention       int
+	TargetGlueRepos             map[GlueRepoKey]glue.GlueClient
+	Report                      *report.Report
+	Logger                      *logger.BuiltinLogger
 }
 
-func NewGlueConnector(prefixForUpdate, overwriteMode string, logger *logger.BuiltinLogger) (GlueConnector, error) {
+const defaultDeprecationParameterKey = "qdic_deprecated"
+
+func NewGlueConnector(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, logger *logger.BuiltinLogger) (GlueConnector, error) {
 	iamRoleARN := os.Getenv("AWS_IAM_ROLE_FOR_GLUE_TABLE")
 	profileName := os.Getenv("PROFILE_NAME")
 	athenaAccountID := os.Getenv("ATHENA_ACCOUNT_ID")
-	glueClient, err := glue.NewGlueClient(iamRoleARN, profileName)
+	deprecationParameterKey := os.Getenv("GLUE_DEPRECATION_PARAMETER_KEY")
+	if deprecationParameterKey == "" {
+		deprecationParameterKey = defaultDeprecationParameterKey
+	}
+	region := os.Getenv("GLUE_REGION")
+	if region == "" {
+		region = glue.DefaultRegion
+	}
+	catalogTargets, err := LoadCatalogTargets(os.Getenv("GLUE_CATALOG_TARGETS_FILE"), region)
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to load CatalogTargets in Glue Connector %s", err)
+	}
+	// MEMO: The account, the region and the role in the environment variables are the only target without the file.
+	targets := catalogTargets.Targets
+	if len(targets) == 0 {
+		targets = []CatalogTarget{{AccountID: athenaAccountID, Region: region, RoleARN: iamRoleARN}}
+	}
+	for i, target := range targets {
+		targets[i].glueRepo, err = glue.NewGlueClient(target.RoleARN, profileName, target.Region)
+		if err != nil {
+			return GlueConnector{}, err
+		}
+		targets[i].lakeFormationRepo, err = lakeformation.NewLakeFormationClient(target.RoleARN, profileName, target.Region)
+		if err != nil {
+			return GlueConnector{}, err
+		}
+	}
+	bulkReadThreshold, err := parseBulkReadThreshold(os.Getenv("GLUE_BULK_READ_THRESHOLD"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to parse GLUE_BULK_READ_THRESHOLD in Glue Connector %s", err)
+	}
+	tableVersionRetention, err := parseTableVersionRetention(os.Getenv("GLUE_TABLE_VERSION_RETENTION"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to parse GLUE_TABLE_VERSION_RETENTION in Glue Connector %s", err)
+	}
+	resourceLinkPolicy, err := parseResourceLinkPolicy(os.Getenv("GLUE_RESOURCE_LINK_POLICY"))
 	if err != nil {
-		return GlueConnector{}, err
+		return GlueConnector{}, fmt.Errorf("Failed to parse GLUE_RESOURCE_LINK_POLICY in Glue Connector %s", err)
+	}
+	targetAccountRoles, err := parseAccountRoles(os.Getenv("GLUE_RESOURCE_LINK_TARGET_ROLES"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to parse GLUE_RESOURCE_LINK_TARGET_ROLES in Glue Connector %s", err)
+	}
+	targetGlueRepoCache := make(map[GlueRepoKey]glue.GlueClient)
+	for i, target := range targets {
+		targets[i].targetGlueRepos, err = genTargetGlueRepos(target, targets, targetAccountRoles, profileName, targetGlueRepoCache)
+		if err != nil {
+			return GlueConnector{}, err
+		}
 	}
 
 	qdcBaseURL := os.Getenv("QDC_BASE_URL")
 	qdcClientID := os.Getenv("QDC_CLIENT_ID")
 	qdcClientSecret := os.Getenv("QDC_CLIENT_SECRET")
 	assetCreatedBy := os.Getenv("QDC_ASSET_CREATED_BY")
+	assetFilter, err := qdc.NewAssetFilter(os.Getenv("QDC_ASSET_FILTER_INCLUDE"), os.Getenv("QDC_ASSET_FILTER_EXCLUDE"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to initialize AssetFilter in Glue Connector %s", err)
+	}
+	descriptionLimiter, err := utils.NewDescriptionLimiter(os.Getenv("DESCRIPTION_LENGTH_LIMITS"), os.Getenv("QDC_ASSET_URL_TEMPLATE"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to initialize DescriptionLimiter in Glue Connector %s", err)
+	}
+	lfTagMapping, err := LoadLFTagMapping(os.Getenv("GLUE_LF_TAG_MAPPING_FILE"))
+	if err != nil {
+		return GlueConnector{}, fmt.Errorf("Failed to load LFTagMapping in Glue Connector %s", err)
+	}
+	metadataParameterKeys := NewMetadataParameterKeys(
+		os.Getenv("GLUE_METADATA_PARAMETER_SYNC") == "true",
+		os.Getenv("GLUE_LOGICAL_NAME_PARAMETER_KEY"),
+		os.Getenv("GLUE_ASSET_ID_PARAMETER_KEY"),
+		os.Getenv("GLUE_SYNCED_AT_PARAMETER_KEY"),
+	)
 	externalAPI, err := qdc.NewQDCExternalAPI(qdcBaseURL, qdcClientID, qdcClientSecret, logger)
 	if err != nil {
 		return GlueConnector{}, fmt.Errorf("Failed to initialize QDCExternalAPI client in Glue Connector %s", err)
 	}
 	connector := GlueConnector{
-		QDCExternalAPIClient: externalAPI,
-		GlueRepo:             glueClient,
-		AssetCreatedBy:       assetCreatedBy,
-		AthenaAccountID:      athenaAccountID,
-		OverwriteMode:        overwriteMode,
-		PrefixForUpdate:      prefixForUpdate,
-		Logger:               logger,
+		QDCExternalAPIClient:        externalAPI,
+		GlueRepo:                    targets[0].glueRepo,
+		LakeFormationRepo:           targets[0].lakeFormationRepo,
+		AssetCreatedBy:              assetCreatedBy,
+		AssetFilter:                 assetFilter,
+		AssetStatePolicy:            assetStatePolicy,
+		AthenaAccountID:             targets[0].AccountID,
+		BulkReadThreshold:           bulkReadThreshold,
+		CatalogTargets:              targets,
+		CommentParameterSyncEnabled: os.Getenv("GLUE_SYNC_TABLE_COMMENT_PARAMETER") == "true",
+		DeprecationParameterKey:     deprecationParameterKey,
+		DescriptionLimiter:          descriptionLimiter,
+		LFTagMapping:                lfTagMapping,
+		MetadataParameterKeys:       metadataParameterKeys,
+		OverwriteMode:               overwriteMode,
+		PrefixForUpdate:             prefixForUpdate,
+		ResourceLinkPolicy:          resourceLinkPolicy,
+		SkipArchiveOnMetadataUpdate: os.Getenv("GLUE_SKIP_ARCHIVE_ON_METADATA_UPDATE") == "true",
+		SkipUnchangedTableUpdate:    os.Getenv("GLUE_SKIP_UNCHANGED_TABLE_UPDATE") == "true",
+		TableVersionRetention:       tableVersionRetention,
+		TargetGlueRepos:             targets[0].targetGlueRepos,
+		Report:                      report.NewReport(),
+		Logger:                      logger,
 	}
 
 	return connector, nil
@@ -64,33 +166,72 @@ func (g *GlueConnector) ReflectDatabaseDescToAthena(dbAssets []qdc.Data) error {
 	mapDBAssetByDBName := mapDBAssetByDBName(allGlueDBs)
 
 	for _, dbAsset := range dbAssets {
-		if dbAsset.IsLost {
-			g.Logger.Debug("Skip schema update because it is lost in qdc : %s", dbAsset.PhysicalName)
+		action := g.AssetStatePolicy.GetAction(dbAsset.IsLost, dbAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			g.Logger.Debug("Skip schema update because it is lost or archived in qdc : %s", dbAsset.PhysicalName)
 			continue
 		}
 
 		if glueDB, ok := mapDBAssetByDBName[dbAsset.PhysicalName]; ok {
+			glueRepo := &g.GlueRepo
+			isLinked := false
+			if target := getDatabaseLinkTarget(g.AthenaAccountID, glueDB); target != nil {
+				targetRepo, targetDB, err := g.resolveDatabaseLink(dbAsset.PhysicalName, target)
+				if err != nil {
+					return err
+				}
+				if targetDB == nil {
+					continue
+				}
+				glueRepo, glueDB, isLinked = targetRepo, *targetDB, true
+			}
 			updateDatabaseInput := genUpdateDatabaseInput(glueDB)
+			databaseShouldBeUpdated := false
+
+			switch action {
+			case utils.AssetStateUpdate:
+				if shouldDatabaseBeUpdated(g.PrefixForUpdate, g.OverwriteMode, glueDB, dbAsset) {
+					descWithPrefix := utils.RenderDescription(g.PrefixForUpdate, dbAsset.Description, utils.FormatPlainText)
+					descWithPrefix = g.fitDescription(utils.FieldGlueDatabaseDescription, dbAsset.PhysicalName, dbAsset.ID, descWithPrefix)
+					updateDatabaseInput.DatabaseInput.Description = &descWithPrefix
+					databaseShouldBeUpdated = true
+				}
+			default:
+				if desc, ok := g.AssetStatePolicy.GenDescription(action, g.PrefixForUpdate, g.OverwriteMode, utils.FormatPlainText, aws.ToString(glueDB.Description), dbAsset.Description); ok {
+					desc = g.fitDescription(utils.FieldGlueDatabaseDescription, dbAsset.PhysicalName, dbAsset.ID, desc)
+					updateDatabaseInput.DatabaseInput.Description = &desc
+					databaseShouldBeUpdated = true
+				}
+			}
+			if parameters, ok := genDeprecationUpdatedParameters(glueDB.Parameters, g.DeprecationParameterKey, action == utils.AssetStateDeprecate); ok {
+				updateDatabaseInput.DatabaseInput.Parameters = parameters
+				databaseShouldBeUpdated = true
+			}
 
-			if shouldDatabaseBeUpdated(g.PrefixForUpdate, g.OverwriteMode, glueDB, dbAsset) {
-				g.Logger.Debug("Database will be updated. name %s", *glueDB.Name)
-				descWithPrefix := utils.AddPrefixToStringIfNotHas(g.PrefixForUpdate, dbAsset.Description)
-				updateDatabaseInput.DatabaseInput.Description = &descWithPrefix
-				_, err := g.GlueRepo.UpdateDatabase(updateDatabaseInput, g.AthenaAccountID)
+			if databaseShouldBeUpdated {
+				g.Logger.Debug("Database will be updated. name %s action %s", *glueDB.Name, action)
+				err := code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+					_, err := glueRepo.UpdateDatabase(updateDatabaseInput, aws.ToString(glueDB.CatalogId))
+					return err
+				})
 				if err != nil {
-					var ge *code.GlueError
-					if errors.As(err, &ge) {
-						if ge.ErrorReason == code.RESOURCE_NOT_FOUND {
-							g.Logger.Warning("Database Not Found in your AWS account. Skip to ingest the table name: %s", *updateDatabaseInput.Name)
-							continue
-						}
+					if err := g.handleAssetError(*updateDatabaseInput.Name, "database", err); err != nil {
+						return err
+					}
+					continue
+				}
+				g.Logger.Debug("Update database. name %s Description: %q -> %q", *glueDB.Name, aws.ToString(glueDB.Description), aws.ToString(updateDatabaseInput.DatabaseInput.Description))
+			}
+			// MEMO: LF-Tags of the shared objects are managed in the catalog of the owning account.
+			if !g.LFTagMapping.IsEmpty() && !isLinked {
+				if err := g.syncDatabaseLFTags(dbAsset.PhysicalName, action, dbAsset); err != nil {
+					if err := g.handleAssetError(dbAsset.PhysicalName, "lf_tag", err); err != nil {
+						g.Logger.Error("Failed to syncDatabaseLFTags. name %s", dbAsset.PhysicalName)
+						return err
 					}
-					return err
 				}
-				g.Logger.Debug("Update database. name %s", *glueDB.Name)
 			}
 		}
-		// Todo: display diff after updating.
 	}
 	return nil
 }
@@ -112,85 +253,155 @@ func (g GlueConnector) GetAllDatabases() ([]types.Database, error) {
 }
 
 func (g *GlueConnector) ReflectTableAttributeToAthena(tableAssets []qdc.Data) error {
+	index, err := g.buildTableIndex(tableAssets, g.BulkReadThreshold)
+	if err != nil {
+		return err
+	}
 	for _, tableAsset := range tableAssets {
-		tableShouldBeUpdated := false
 		databaseAsset := qdc.GetSpecifiedAssetFromPath(tableAsset, "schema3")
 
-		if tableAsset.IsLost {
-			g.Logger.Debug("Skip table update because it is lost in qdc : %s->%s", databaseAsset.Name, tableAsset.PhysicalName)
+		action := g.AssetStatePolicy.GetAction(tableAsset.IsLost, tableAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			g.Logger.Debug("Skip table update because it is lost or archived in qdc : %s->%s", databaseAsset.Name, tableAsset.PhysicalName)
 			continue
 		}
 
-		glueTable, err := g.GlueRepo.GetTable(g.AthenaAccountID, databaseAsset.Name, tableAsset.PhysicalName)
+		var glueTable *glueService.GetTableOutput
+		err := code.DoWithRetry(maxRetryAttempts, retryBackoff, func() error {
+			var err error
+			glueTable, err = g.getTable(index, databaseAsset.Name, tableAsset.PhysicalName)
+			return err
+		})
 		if err != nil {
-			var ge *code.GlueError
-			if errors.As(err, &ge) {
-				if ge.ErrorReason == code.RESOURCE_NOT_FOUND {
-					g.Logger.Warning("Table Not Found in your AWS account. Skip to ingest the table name: %s", tableAsset.PhysicalName)
-					continue
-				}
+			if err := g.handleAssetError(fmt.Sprintf("%s.%s", databaseAsset.Name, tableAsset.PhysicalName), "table", err); err != nil {
+				return err
 			}
-			return err
+			continue
 		}
-		updateTableInput := genUpdateTableInput(glueTable)
-		if shouldTableBeUpdated(g.PrefixForUpdate, g.OverwriteMode, glueTable.Table, tableAsset) {
-			descWithPrefix := utils.AddPrefixToStringIfNotHas(g.PrefixForUpdate, tableAsset.Description)
-			g.Logger.Debug("Table will be updated: %s", *glueTable.Table.Name)
-			updateTableInput.TableInput.Description = &descWithPrefix
-			tableShouldBeUpdated = true
+		tableFQN := fmt.Sprintf("%s.%s", databaseAsset.Name, tableAsset.PhysicalName)
+		glueRepo := &g.GlueRepo
+		isLinked := false
+		if target := getTableLinkTarget(g.AthenaAccountID, glueTable.Table); target != nil {
+			targetRepo, targetTable, err := g.resolveTableLink(tableFQN, target)
+			if err != nil {
+				return err
+			}
+			if targetTable == nil {
+				continue
+			}
+			glueRepo, glueTable, isLinked = targetRepo, targetTable, true
+		}
+		tableFormat := detectTableFormat(glueTable.Table)
+		skipTableReason, skipColumnReason := checkOpenTableFormat(tableFormat, glueTable.Table)
+		if skipTableReason != "" {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "table_format", fmt.Sprintf("Skipped the %s table. %s", tableFormat, skipTableReason))
+			continue
 		}
 		columnAssets, err := g.QDCExternalAPIClient.GetChildAssetsByParentAsset(tableAsset)
 		if err != nil {
 			return err
 		}
-		updatedColumns, columnShouldBeUpdated := getDescUpdatedColumns(g.PrefixForUpdate, g.OverwriteMode, glueTable, columnAssets)
-		if columnShouldBeUpdated {
-			updateTableInput.TableInput.StorageDescriptor.Columns = updatedColumns
+		// MEMO: The include conditions that matched the table aren't applied to its columns again.
+		columnAssets = g.AssetFilter.FilterAssetsInScope(columnAssets)
+		storageColumnAssets := columnAssets
+		if skipColumnReason != "" {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "table_format", fmt.Sprintf("Skipped the columns of the %s table. %s", tableFormat, skipColumnReason))
+			storageColumnAssets = nil
 		}
-		if tableShouldBeUpdated || columnShouldBeUpdated {
-			_, err = g.GlueRepo.UpdateTable(g.AthenaAccountID, databaseAsset.Name, updateTableInput)
-			if err != nil {
-				return err
+		glueTable, ok, err := g.updateTable(glueRepo, glueTable, tableAsset, columnAssets, storageColumnAssets, action, tableFQN)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if !g.LFTagMapping.IsEmpty() && !isLinked {
+			if err := g.syncTableLFTags(glueTable, action, tableAsset, columnAssets); err != nil {
+				if err := g.handleAssetError(tableFQN, "lf_tag", err); err != nil {
+					g.Logger.Error("Failed to syncTableLFTags. table name %s", tableAsset.PhysicalName)
+					return err
+				}
 			}
-			msg := genUpdateMessage(tableShouldBeUpdated, columnShouldBeUpdated)
-			g.Logger.Debug("Update table. msg: %s table name %s", msg, tableAsset.PhysicalName)
 		}
-		// Todo: validate table def by compare the output and previous version.
 	}
 	return nil
 }
 
 func (g *GlueConnector) ReflectMetadataToDataCatalog() error {
+	defer g.Report.Print(g.Logger)
 	g.Logger.Info("List Athena database assets")
 	rootAssets, err := g.QDCExternalAPIClient.GetAllRootAssets("athena", g.AssetCreatedBy)
 	if err != nil {
 		g.Logger.Error("Failed to GetAllAthenaRootAssets: %s", err.Error())
 		return err
 	}
+	if !g.LFTagMapping.IsEmpty() {
+		g.Logger.Info("Get QDIC tag dictionary")
+		tagDictionary, err := g.QDCExternalAPIClient.GetTagDictionary()
+		if err != nil {
+			g.Logger.Error("Failed to GetTagDictionary: %s", err.Error())
+			return err
+		}
+		g.TagDictionary = tagDictionary
+		if err := qdc.ResolveTagNames(tagDictionary, g.LFTagMapping.TagSelectors()); err != nil {
+			g.Logger.Error("Failed to resolve the tag names in the LF-Tag mapping: %s", err.Error())
+			return err
+		}
+	}
+	rootAssetsByTarget, unmatchedRootAssets := MatchRootAssets(g.CatalogTargets, rootAssets)
+	for _, rootAsset := range unmatchedRootAssets {
+		g.Report.Add(report.WARNING, "athena", rootAsset.PhysicalName, "catalog_target", "Skipped the root asset because it doesn't match any catalog target.")
+	}
+	for i, target := range g.CatalogTargets {
+		if len(rootAssetsByTarget[i]) == 0 {
+			g.Logger.Debug("Skip the catalog %s because no root asset matches it", target.String())
+			continue
+		}
+		g.Logger.Info("Start to sync the catalog %s", target.String())
+		err := g.withCatalogTarget(target).reflectCatalog(rootAssetsByTarget[i])
+		if err != nil {
+			g.Logger.Error("Failed to sync the catalog %s: %s", target.String(), err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// reflectCatalog syncs the databases and the tables under the root assets to the catalog of the connector.
+func (g *GlueConnector) reflectCatalog(rootAssets []qdc.Data) error {
+	if !g.LFTagMapping.IsEmpty() {
+		g.Logger.Info("Ensure LF-Tags in the mapping")
+		err := g.ensureLFTags()
+		if err != nil {
+			g.Logger.Error("Failed to ensureLFTags: %s", err.Error())
+			return err
+		}
+	}
 
 	g.Logger.Info("List Athena schema assets")
-	schemaAssets, err := g.QDCExternalAPIClient.GetAllChildAssetsByID(rootAssets)
+	schemaAssets, err := g.QDCExternalAPIClient.GetAllChildAssetsByID(g.AssetFilter.FilterAssetsInScope(rootAssets))
 	if err != nil {
 		g.Logger.Error("Failed to GetAllChildAssetsByID for schemaAssets: %s", err.Error())
 		return err
 	}
 
 	g.Logger.Info("Start to run ReflectDatabaseDescToAthena")
-	err = g.ReflectDatabaseDescToAthena(schemaAssets)
+	err = g.ReflectDatabaseDescToAthena(g.AssetFilter.FilterAssets(schemaAssets))
 	if err != nil {
 		g.Logger.Error("Failed to ReflectDatabaseDescToAthena for schemaAssets: %s", err.Error())
 		return err
 	}
 
 	g.Logger.Info("List Athena table assets")
-	tableAssets, err := g.QDCExternalAPIClient.GetAllChildAssetsByID(schemaAssets)
+	// MEMO: The tables of the databases out of the scope of AssetFilter aren't read.
+	tableAssets, err := g.QDCExternalAPIClient.GetAllChildAssetsByID(g.AssetFilter.FilterAssetsInScope(schemaAssets))
 	if err != nil {
 		g.Logger.Error("Failed to GetAllChildAssetsByID: %s", err.Error())
 		return err
 	}
 
 	g.Logger.Info("Start to run ReflectTableAttributeToAthena")
-	err = g.ReflectTableAttributeToAthena(tableAssets)
+	err = g.ReflectTableAttributeToAthena(g.AssetFilter.FilterAssets(tableAssets))
 	if err != nil {
 		g.Logger.Error("Failed to ReflectTableAttributeToAthena: %s", err.Error())
 		return err
@@ -198,26 +409,78 @@ func (g *GlueConnector) ReflectMetadataToDataCatalog() error {
 	return nil
 }
 
-func getDescUpdatedColumns(prefixForUpdate, overwriteMode string, glueTable *glueService.GetTableOutput, columnAssets []qdc.Data) ([]types.Column, bool) {
-	var updatedColumns []types.Column
-	shouldBeUpdated := false
+// handleAssetError reports the error and returns nil if the asset can be skipped. Otherwise, it returns the error to abort the run.
+func (g *GlueConnector) handleAssetError(target, field string, err error) error {
+	if code.Decide(err) != code.Skip {
+		return err
+	}
+	g.Report.Add(report.WARNING, "athena", target, field, fmt.Sprintf("Skipped the update. %s", err.Error()))
+	return nil
+}
+
+// fitDescription truncates the value to the limit of the Glue field and reports the truncation.
+func (g *GlueConnector) fitDescription(field, target, assetID, desc string) string {
+	fitted, truncated := g.DescriptionLimiter.Fit(field, desc, assetID)
+	if truncated {
+		g.Report.Add(report.WARNING, "athena", target, field, fmt.Sprintf("Truncated from %d to %d characters.", utf8.RuneCountInString(desc), utf8.RuneCountInString(fitted)))
+	}
+	return fitted
+}
+
+func (g *GlueConnector) fitColumnComments(tableFQN string, columns []types.Column, columnAssets []qdc.Data) []types.Column {
 	mapColumnAssetByColumnName := mapColumnAssetByColumnName(columnAssets)
+	for i, column := range columns {
+		if column.Comment == nil || column.Name == nil {
+			continue
+		}
+		columnAsset := mapColumnAssetByColumnName[*column.Name]
+		comment := g.fitDescription(utils.FieldGlueColumnComment, fmt.Sprintf("%s.%s", tableFQN, *column.Name), columnAsset.ID, *column.Comment)
+		columns[i].Comment = &comment
+	}
+	return columns
+}
+
+func getDescUpdatedColumns(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, glueTable *glueService.GetTableOutput, columnAssets []qdc.Data) ([]types.Column, bool) {
 	if glueTable.Table.StorageDescriptor == nil {
 		return []types.Column{}, false
 	}
-	for _, column := range glueTable.Table.StorageDescriptor.Columns {
+	return genDescUpdatedColumns(prefixForUpdate, overwriteMode, assetStatePolicy, glueTable.Table.StorageDescriptor.Columns, columnAssets)
+}
+
+// getDescUpdatedPartitionKeys returns the partition keys whose comments are updated with the same policy as the columns.
+func getDescUpdatedPartitionKeys(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, glueTable *glueService.GetTableOutput, columnAssets []qdc.Data) ([]types.Column, bool) {
+	if len(glueTable.Table.PartitionKeys) == 0 {
+		return []types.Column{}, false
+	}
+	return genDescUpdatedColumns(prefixForUpdate, overwriteMode, assetStatePolicy, glueTable.Table.PartitionKeys, columnAssets)
+}
+
+func genDescUpdatedColumns(prefixForUpdate, overwriteMode string, assetStatePolicy utils.AssetStatePolicy, columns []types.Column, columnAssets []qdc.Data) ([]types.Column, bool) {
+	var updatedColumns []types.Column
+	shouldBeUpdated := false
+	mapColumnAssetByColumnName := mapColumnAssetByColumnName(columnAssets)
+	for _, column := range columns {
 		var columnName string
 		if column.Name != nil {
 			columnName = *column.Name
 		}
 		if columnAsset, ok := mapColumnAssetByColumnName[columnName]; ok {
-			if shouldColumnBeUpdated(prefixForUpdate, overwriteMode, column, columnAsset) {
+			action := assetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+			switch {
+			case action == utils.AssetStateUpdate && shouldColumnBeUpdated(prefixForUpdate, overwriteMode, column, columnAsset):
 				updatedColumn := column
-				descWithPrefix := utils.AddPrefixToStringIfNotHas(prefixForUpdate, columnAsset.Description)
+				descWithPrefix := utils.RenderDescription(prefixForUpdate, columnAsset.Description, utils.FormatPlainText)
 				updatedColumn.Comment = &descWithPrefix
 				updatedColumns = append(updatedColumns, updatedColumn)
 				shouldBeUpdated = true
-			} else {
+			case action != utils.AssetStateUpdate:
+				updatedColumn := column
+				if desc, ok := assetStatePolicy.GenDescription(action, prefixForUpdate, overwriteMode, utils.FormatPlainText, aws.ToString(column.Comment), columnAsset.Description); ok {
+					updatedColumn.Comment = &desc
+					shouldBeUpdated = true
+				}
+				updatedColumns = append(updatedColumns, updatedColumn)
+			default:
 				updatedColumns = append(updatedColumns, column)
 			}
 		} else {
@@ -247,6 +510,25 @@ func mapDBAssetByDBName(databases []types.Database) map[string]types.Database {
 	return mapDBAssetByDBName
 }
 
+// genDeprecationUpdatedParameters returns a copy of the parameters where the deprecation marker is set or removed.
+// The second return value is false when the parameters don't have to be changed.
+func genDeprecationUpdatedParameters(parameters map[string]string, key string, isDeprecated bool) (map[string]string, bool) {
+	_, hasKey := parameters[key]
+	if key == "" || isDeprecated == hasKey {
+		return parameters, false
+	}
+	updatedParameters := make(map[string]string)
+	for k, v := range parameters {
+		updatedParameters[k] = v
+	}
+	if isDeprecated {
+		updatedParameters[key] = "true"
+	} else {
+		delete(updatedParameters, key)
+	}
+	return updatedParameters, true
+}
+
 func genUpdateMessage(tableUpdated, columnUpdated bool) string {
 	var message string
 	switch {
@@ -298,6 +580,11 @@ func genUpdateTableInput(getTableOutput *glueService.GetTableOutput) glueService
 			tableInputFieldValue.Set(valueOfGetTableOutput)
 		}
 	}
+	// MEMO: The storage descriptor is copied, so that the changes to the input don't modify the table that was read.
+	if tableInput.StorageDescriptor != nil {
+		storageDescriptor := *tableInput.StorageDescriptor
+		tableInput.StorageDescriptor = &storageDescriptor
+	}
 	updateTableInput := glueService.UpdateTableInput{
 		CatalogId:    getTableOutput.Table.CatalogId,
 		DatabaseName: getTableOutput.Table.DatabaseName,
@@ -296,13 +296,48 @@ func TestGetDescUpdatedColumns(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		res, b := getDescUpdatedColumns("【QDIC】", utils.OverwriteIfEmpty, testCase.Input.GlueTable, testCase.Input.ColumnAssets)
+		res, b := getDescUpdatedColumns("【QDIC】", utils.OverwriteIfEmpty, utils.DefaultAssetStatePolicy(), testCase.Input.GlueTable, testCase.Input.ColumnAssets)
 		if !reflect.DeepEqual(res, testCase.Expect.Columns) || b != testCase.Expect.ShouldBeUpdated {
 			t.Errorf("want %v but got %v.", testCase.Expect, res)
 		}
 	}
 }
 
+func TestGetDescUpdatedPartitionKeys(t *testing.T) {
+	glueTable := &glueService.GetTableOutput{
+		Table: &types.Table{
+			Name: genStringPointer("test-table1"),
+			StorageDescriptor: &types.StorageDescriptor{
+				Columns: []types.Column{
+					{Name: genStringPointer("test-column1"), Comment: nil},
+				},
+			},
+			PartitionKeys: []types.Column{
+				{Name: genStringPointer("dt"), Comment: nil},
+				{Name: genStringPointer("region"), Comment: genStringPointer("region-comment")},
+			},
+		},
+	}
+	columnAssets := []qdc.Data{
+		{PhysicalName: "test-column1", Description: "test-column-comment-qdc1"},
+		{PhysicalName: "dt", Description: "partition date"},
+		{PhysicalName: "region", Description: "partition region"},
+	}
+	expect := []types.Column{
+		{Name: genStringPointer("dt"), Comment: genStringPointer("【QDIC】partition date")},
+		{Name: genStringPointer("region"), Comment: genStringPointer("region-comment")},
+	}
+	res, b := getDescUpdatedPartitionKeys("【QDIC】", utils.OverwriteIfEmpty, utils.DefaultAssetStatePolicy(), glueTable, columnAssets)
+	if !reflect.DeepEqual(res, expect) || !b {
+		t.Errorf("want %v but got %v.", expect, res)
+	}
+
+	res, b = getDescUpdatedPartitionKeys("【QDIC】", utils.OverwriteIfEmpty, utils.DefaultAssetStatePolicy(), &glueService.GetTableOutput{Table: &types.Table{}}, columnAssets)
+	if len(res) != 0 || b {
+		t.Errorf("want no partition keys but got %v.", res)
+	}
+}
+
 func TestGenUpdateMessage(t *testing.T) {
 	testCases := []struct {
 		Input struct {
@@ -1385,3 +1420,57 @@ func TestShouldColumnBeUpdated(t *testing.T) {
 func genStringPointer(s string) *string {
 	return &s
 }
+
+func TestGenDeprecationUpdatedParameters(t *testing.T) {
+	testCases := []struct {
+		name         string
+		parameters   map[string]string
+		isDeprecated bool
+		want         map[string]string
+		wantUpdate   bool
+	}{
+		{
+			name:         "set the marker",
+			parameters:   map[string]string{"classification": "parquet"},
+			isDeprecated: true,
+			want:         map[string]string{"classification": "parquet", "qdic_deprecated": "true"},
+			wantUpdate:   true,
+		},
+		{
+			name:         "set the marker to nil parameters",
+			parameters:   nil,
+			isDeprecated: true,
+			want:         map[string]string{"qdic_deprecated": "true"},
+			wantUpdate:   true,
+		},
+		{
+			name:         "remove the marker",
+			parameters:   map[string]string{"classification": "parquet", "qdic_deprecated": "true"},
+			isDeprecated: false,
+			want:         map[string]string{"classification": "parquet"},
+			wantUpdate:   true,
+		},
+		{
+			name:         "nothing to change",
+			parameters:   map[string]string{"classification": "parquet"},
+			isDeprecated: false,
+			want:         map[string]string{"classification": "parquet"},
+			wantUpdate:   false,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]string)
+			for k, v := range tt.parameters {
+				original[k] = v
+			}
+			res, shouldUpdate := genDeprecationUpdatedParameters(tt.parameters, "qdic_deprecated", tt.isDeprecated)
+			if !reflect.DeepEqual(res, tt.want) || shouldUpdate != tt.wantUpdate {
+				t.Errorf("want %v, %v but got %v, %v", tt.want, tt.wantUpdate, res, shouldUpdate)
+			}
+			if len(tt.parameters) != len(original) {
+				t.Errorf("input parameters must not be modified. got %v", tt.parameters)
+			}
+		})
+	}
+}
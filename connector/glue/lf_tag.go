@@ -0,0 +1,290 @@
+package glue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	lfTypes "github.com/aws/aws-sdk-go-v2/service/lakeformation/types"
+)
+
+// LFTagMapping maps QDIC tags to Lake Formation LF-Tags.
+// Only the LF-Tag keys in the mapping are owned by the agent. The other keys are never changed.
+type LFTagMapping struct {
+	Mappings []LFTagMappingRule `json:"mappings"`
+}
+
+// LFTagMappingRule assigns the LF-Tag to the assets that have the selected QDIC tag.
+// MEMO: A resource can have only one value for an LF-Tag key. The first matched rule of the key is used.
+type LFTagMappingRule struct {
+	qdc.TagSelector
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func LoadLFTagMapping(path string) (LFTagMapping, error) {
+	if path == "" {
+		return LFTagMapping{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return LFTagMapping{}, err
+	}
+	var mapping LFTagMapping
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return LFTagMapping{}, fmt.Errorf("invalid LF-Tag mapping %s: %s", path, err)
+	}
+	for i, rule := range mapping.Mappings {
+		if rule.TagSelector.IsEmpty() {
+			return LFTagMapping{}, fmt.Errorf("mapping %d has none of tag_group_id, tag_id, tag_group_name and tag_name", i)
+		}
+		if rule.Key == "" || rule.Value == "" {
+			return LFTagMapping{}, fmt.Errorf("mapping %d needs both of key and value", i)
+		}
+	}
+	return mapping, nil
+}
+
+func (m LFTagMapping) IsEmpty() bool {
+	return len(m.Mappings) == 0
+}
+
+// TagSelectors returns the selectors of the rules, so that the tag names in them are resolved.
+func (m *LFTagMapping) TagSelectors() []*qdc.TagSelector {
+	selectors := make([]*qdc.TagSelector, 0, len(m.Mappings))
+	for i := range m.Mappings {
+		selectors = append(selectors, &m.Mappings[i].TagSelector)
+	}
+	return selectors
+}
+
+func (m LFTagMapping) IsManaged(key string) bool {
+	for _, rule := range m.Mappings {
+		if rule.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ValuesByKey returns the values of each LF-Tag key in the mapping without duplication.
+func (m LFTagMapping) ValuesByKey() map[string][]string {
+	valuesByKey := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, rule := range m.Mappings {
+		if seen[rule.Key+"\x00"+rule.Value] {
+			continue
+		}
+		seen[rule.Key+"\x00"+rule.Value] = true
+		valuesByKey[rule.Key] = append(valuesByKey[rule.Key], rule.Value)
+	}
+	return valuesByKey
+}
+
+// GenLFTags returns the LF-Tag values by key for the asset.
+func (m LFTagMapping) GenLFTags(asset qdc.Data) map[string]string {
+	lfTags := make(map[string]string)
+	for _, rule := range m.Mappings {
+		if _, ok := lfTags[rule.Key]; ok {
+			continue
+		}
+		if rule.Matches(asset) {
+			lfTags[rule.Key] = rule.Value
+		}
+	}
+	return lfTags
+}
+
+// genLFTagChanges returns the LF-Tags to be removed from and added to the resource.
+// The managed keys whose value changes are removed before the new value is added.
+func genLFTagChanges(mapping LFTagMapping, current []lfTypes.LFTagPair, desired map[string]string) (toRemove, toAdd []lfTypes.LFTagPair) {
+	currentValues := make(map[string]string)
+	for _, pair := range current {
+		key := aws.ToString(pair.TagKey)
+		if !mapping.IsManaged(key) || len(pair.TagValues) == 0 {
+			continue
+		}
+		currentValues[key] = pair.TagValues[0]
+		if desired[key] != pair.TagValues[0] {
+			toRemove = append(toRemove, lfTypes.LFTagPair{TagKey: aws.String(key), TagValues: pair.TagValues})
+		}
+	}
+	for key, value := range desired {
+		if currentValues[key] == value {
+			continue
+		}
+		toAdd = append(toAdd, lfTypes.LFTagPair{TagKey: aws.String(key), TagValues: []string{value}})
+	}
+	sortLFTagPairs(toRemove)
+	sortLFTagPairs(toAdd)
+	return toRemove, toAdd
+}
+
+func sortLFTagPairs(pairs []lfTypes.LFTagPair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		return aws.ToString(pairs[i].TagKey) < aws.ToString(pairs[j].TagKey)
+	})
+}
+
+// ensureLFTags creates the LF-Tag keys in the mapping, or adds the missing values to them.
+func (g *GlueConnector) ensureLFTags() error {
+	valuesByKey := g.LFTagMapping.ValuesByKey()
+	keys := make([]string, 0, len(valuesByKey))
+	for key := range valuesByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := valuesByKey[key]
+		lfTag, err := g.LakeFormationRepo.GetLFTag(g.AthenaAccountID, key)
+		if err != nil {
+			return err
+		}
+		if lfTag == nil {
+			err := g.LakeFormationRepo.CreateLFTag(g.AthenaAccountID, key, values)
+			if err == nil {
+				g.Report.Add(report.INFO, "athena", key, "lf_tag", fmt.Sprintf("Created LF-Tag with values %v.", values))
+				continue
+			}
+			if !code.Is(err, code.AlreadyExists) {
+				return err
+			}
+			// MEMO: Another run created the LF-Tag after the read. It is read again to add the missing values.
+			lfTag, err = g.LakeFormationRepo.GetLFTag(g.AthenaAccountID, key)
+			if err != nil {
+				return err
+			}
+			if lfTag == nil {
+				continue
+			}
+		}
+		existing := make(map[string]bool)
+		for _, value := range lfTag.TagValues {
+			existing[value] = true
+		}
+		var missingValues []string
+		for _, value := range values {
+			if !existing[value] {
+				missingValues = append(missingValues, value)
+			}
+		}
+		if len(missingValues) == 0 {
+			continue
+		}
+		if err := g.LakeFormationRepo.AddLFTagValues(g.AthenaAccountID, key, missingValues); err != nil {
+			return err
+		}
+		g.Report.Add(report.INFO, "athena", key, "lf_tag", fmt.Sprintf("Added values %v to LF-Tag.", missingValues))
+	}
+	return nil
+}
+
+// genDesiredLFTags returns the LF-Tags for the asset. The managed LF-Tags are removed from the assets with CLEAR action.
+func (g *GlueConnector) genDesiredLFTags(action string, asset qdc.Data) map[string]string {
+	if action == utils.AssetStateClear {
+		return map[string]string{}
+	}
+	return g.LFTagMapping.GenLFTags(asset)
+}
+
+// applyLFTagChanges updates the LF-Tags of the resource and reports what changed with the QDIC tags of the asset.
+func (g *GlueConnector) applyLFTagChanges(resource *lfTypes.Resource, target string, current []lfTypes.LFTagPair, action string, asset qdc.Data) error {
+	desired := g.genDesiredLFTags(action, asset)
+	qdcTagNames := g.TagDictionary.TagNames(asset)
+	toRemove, toAdd := genLFTagChanges(g.LFTagMapping, current, desired)
+	if len(toRemove) > 0 {
+		if err := g.LakeFormationRepo.RemoveLFTagsFromResource(g.AthenaAccountID, resource, toRemove); err != nil {
+			return err
+		}
+		for _, pair := range toRemove {
+			g.Report.Add(report.INFO, "athena", target, "lf_tag", fmt.Sprintf("Removed %s=%v. QDIC tags %v.", aws.ToString(pair.TagKey), pair.TagValues, qdcTagNames))
+		}
+	}
+	if len(toAdd) > 0 {
+		if err := g.LakeFormationRepo.AddLFTagsToResource(g.AthenaAccountID, resource, toAdd); err != nil {
+			return err
+		}
+		for _, pair := range toAdd {
+			g.Report.Add(report.INFO, "athena", target, "lf_tag", fmt.Sprintf("Assigned %s=%v. QDIC tags %v.", aws.ToString(pair.TagKey), pair.TagValues, qdcTagNames))
+		}
+	}
+	return nil
+}
+
+func (g *GlueConnector) syncDatabaseLFTags(dbName string, action string, dbAsset qdc.Data) error {
+	resource := &lfTypes.Resource{
+		Database: &lfTypes.DatabaseResource{
+			CatalogId: &g.AthenaAccountID,
+			Name:      aws.String(dbName),
+		},
+	}
+	output, err := g.LakeFormationRepo.GetResourceLFTags(g.AthenaAccountID, resource)
+	if err != nil {
+		return err
+	}
+	return g.applyLFTagChanges(resource, dbName, output.LFTagOnDatabase, action, dbAsset)
+}
+
+// syncTableLFTags updates the LF-Tags of the table and the columns that exist in the Glue table.
+func (g *GlueConnector) syncTableLFTags(glueTable *glueService.GetTableOutput, action string, tableAsset qdc.Data, columnAssets []qdc.Data) error {
+	dbName := aws.ToString(glueTable.Table.DatabaseName)
+	tableName := aws.ToString(glueTable.Table.Name)
+	tableFQN := fmt.Sprintf("%s.%s", dbName, tableName)
+	tableResource := &lfTypes.Resource{
+		Table: &lfTypes.TableResource{
+			CatalogId:    &g.AthenaAccountID,
+			DatabaseName: aws.String(dbName),
+			Name:         aws.String(tableName),
+		},
+	}
+	output, err := g.LakeFormationRepo.GetResourceLFTags(g.AthenaAccountID, tableResource)
+	if err != nil {
+		return err
+	}
+	if err := g.applyLFTagChanges(tableResource, tableFQN, output.LFTagsOnTable, action, tableAsset); err != nil {
+		return err
+	}
+
+	currentByColumn := make(map[string][]lfTypes.LFTagPair)
+	for _, columnLFTag := range output.LFTagsOnColumns {
+		currentByColumn[aws.ToString(columnLFTag.Name)] = columnLFTag.LFTags
+	}
+	glueColumns := make(map[string]bool)
+	if glueTable.Table.StorageDescriptor != nil {
+		for _, column := range glueTable.Table.StorageDescriptor.Columns {
+			glueColumns[aws.ToString(column.Name)] = true
+		}
+	}
+	for _, column := range glueTable.Table.PartitionKeys {
+		glueColumns[aws.ToString(column.Name)] = true
+	}
+	for _, columnAsset := range columnAssets {
+		if !glueColumns[columnAsset.PhysicalName] {
+			continue
+		}
+		columnAction := g.AssetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+		if columnAction == utils.AssetStateSkip {
+			continue
+		}
+		columnResource := &lfTypes.Resource{
+			TableWithColumns: &lfTypes.TableWithColumnsResource{
+				CatalogId:    &g.AthenaAccountID,
+				DatabaseName: aws.String(dbName),
+				Name:         aws.String(tableName),
+				ColumnNames:  []string{columnAsset.PhysicalName},
+			},
+		}
+		target := fmt.Sprintf("%s.%s", tableFQN, columnAsset.PhysicalName)
+		if err := g.applyLFTagChanges(columnResource, target, currentByColumn[columnAsset.PhysicalName], columnAction, columnAsset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
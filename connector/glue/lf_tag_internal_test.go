@@ -0,0 +1,85 @@
+package glue
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	lfTypes "github.com/aws/aws-sdk-go-v2/service/lakeformation/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testLFTagMapping = LFTagMapping{
+	Mappings: []LFTagMappingRule{
+		{TagSelector: qdc.TagSelector{TagID: "tag-pii"}, Key: "sensitivity", Value: "confidential"},
+		{TagSelector: qdc.TagSelector{TagGroupID: "tggr-1"}, Key: "sensitivity", Value: "internal"},
+		{TagSelector: qdc.TagSelector{TagGroupID: "tggr-2"}, Key: "domain", Value: "sales"},
+	},
+}
+
+func TestLFTagMappingGenLFTags(t *testing.T) {
+	asset := qdc.Data{RuleTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-pii"}}}
+	want := map[string]string{"sensitivity": "confidential"}
+	if diff := cmp.Diff(want, testLFTagMapping.GenLFTags(asset)); diff != "" {
+		t.Errorf("GenLFTags mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLFTagMappingValuesByKey(t *testing.T) {
+	want := map[string][]string{
+		"sensitivity": {"confidential", "internal"},
+		"domain":      {"sales"},
+	}
+	if diff := cmp.Diff(want, testLFTagMapping.ValuesByKey()); diff != "" {
+		t.Errorf("ValuesByKey mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenLFTagChanges(t *testing.T) {
+	pairsToMap := func(pairs []lfTypes.LFTagPair) map[string][]string {
+		m := make(map[string][]string)
+		for _, pair := range pairs {
+			m[aws.ToString(pair.TagKey)] = pair.TagValues
+		}
+		return m
+	}
+	current := []lfTypes.LFTagPair{
+		{TagKey: aws.String("sensitivity"), TagValues: []string{"internal"}},
+		{TagKey: aws.String("domain"), TagValues: []string{"sales"}},
+		{TagKey: aws.String("owner"), TagValues: []string{"data-team"}},
+	}
+	testCases := []struct {
+		name       string
+		desired    map[string]string
+		wantRemove map[string][]string
+		wantAdd    map[string][]string
+	}{
+		{
+			name:       "no change",
+			desired:    map[string]string{"sensitivity": "internal", "domain": "sales"},
+			wantRemove: map[string][]string{},
+			wantAdd:    map[string][]string{},
+		},
+		{
+			name:       "value changed and key removed",
+			desired:    map[string]string{"sensitivity": "confidential"},
+			wantRemove: map[string][]string{"sensitivity": {"internal"}, "domain": {"sales"}},
+			wantAdd:    map[string][]string{"sensitivity": {"confidential"}},
+		},
+		{
+			name:       "clear",
+			desired:    map[string]string{},
+			wantRemove: map[string][]string{"sensitivity": {"internal"}, "domain": {"sales"}},
+			wantAdd:    map[string][]string{},
+		},
+	}
+	for _, tt := range testCases {
+		toRemove, toAdd := genLFTagChanges(testLFTagMapping, current, tt.desired)
+		if diff := cmp.Diff(tt.wantRemove, pairsToMap(toRemove)); diff != "" {
+			t.Errorf("%s: toRemove mismatch (-want +got):\n%s", tt.name, diff)
+		}
+		if diff := cmp.Diff(tt.wantAdd, pairsToMap(toAdd)); diff != "" {
+			t.Errorf("%s: toAdd mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package glue
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+const (
+	defaultLogicalNameParameterKey = "qdic_logical_name"
+	defaultAssetIDParameterKey     = "qdic_asset_id"
+	defaultSyncedAtParameterKey    = "qdic_synced_at"
+)
+
+// MetadataParameterKeys is the keys of the table and column parameters where QDIC metadata is written.
+// The parameters are not written when Enabled is false.
+type MetadataParameterKeys struct {
+	Enabled     bool
+	LogicalName string
+	AssetID     string
+	SyncedAt    string
+}
+
+func NewMetadataParameterKeys(enabled bool, logicalNameKey, assetIDKey, syncedAtKey string) MetadataParameterKeys {
+	if logicalNameKey == "" {
+		logicalNameKey = defaultLogicalNameParameterKey
+	}
+	if assetIDKey == "" {
+		assetIDKey = defaultAssetIDParameterKey
+	}
+	if syncedAtKey == "" {
+		syncedAtKey = defaultSyncedAtParameterKey
+	}
+	return MetadataParameterKeys{
+		Enabled:     enabled,
+		LogicalName: logicalNameKey,
+		AssetID:     assetIDKey,
+		SyncedAt:    syncedAtKey,
+	}
+}
+
+// genMetadataUpdatedParameters returns a copy of the parameters where the logical name and the asset ID of the asset are set.
+// The values are removed when clear is true. The sync time is refreshed only when the other values are changed,
+// so that the parameters are not rewritten in every run.
+// The second return value is false when the parameters don't have to be changed.
+func genMetadataUpdatedParameters(parameters map[string]string, keys MetadataParameterKeys, asset qdc.Data, clear bool, syncedAt time.Time) (map[string]string, bool) {
+	if !keys.Enabled {
+		return parameters, false
+	}
+	desired := map[string]string{
+		keys.LogicalName: asset.LogicalName,
+		keys.AssetID:     asset.ID,
+	}
+	if clear {
+		desired = map[string]string{keys.LogicalName: "", keys.AssetID: "", keys.SyncedAt: ""}
+	}
+	shouldBeUpdated := false
+	for key, value := range desired {
+		current, hasKey := parameters[key]
+		if (value == "" && hasKey) || (value != "" && current != value) {
+			shouldBeUpdated = true
+		}
+	}
+	if !shouldBeUpdated {
+		return parameters, false
+	}
+	updatedParameters := make(map[string]string)
+	for k, v := range parameters {
+		updatedParameters[k] = v
+	}
+	for key, value := range desired {
+		if value == "" {
+			delete(updatedParameters, key)
+		} else {
+			updatedParameters[key] = value
+		}
+	}
+	if !clear {
+		updatedParameters[keys.SyncedAt] = syncedAt.UTC().Format(time.RFC3339)
+	}
+	return updatedParameters, true
+}
+
+// genMetadataUpdatedColumns returns a copy of the columns where QDIC metadata is written into the parameters.
+// The second return value is false when none of the columns have to be changed.
+func genMetadataUpdatedColumns(keys MetadataParameterKeys, assetStatePolicy utils.AssetStatePolicy, columns []types.Column, columnAssets []qdc.Data, syncedAt time.Time) ([]types.Column, bool) {
+	if !keys.Enabled {
+		return columns, false
+	}
+	mapColumnAssetByColumnName := mapColumnAssetByColumnName(columnAssets)
+	updatedColumns := make([]types.Column, len(columns))
+	shouldBeUpdated := false
+	for i, column := range columns {
+		updatedColumns[i] = column
+		columnAsset, ok := mapColumnAssetByColumnName[aws.ToString(column.Name)]
+		if !ok {
+			continue
+		}
+		action := assetStatePolicy.GetAction(columnAsset.IsLost, columnAsset.IsArchived)
+		if action == utils.AssetStateSkip {
+			continue
+		}
+		if parameters, ok := genMetadataUpdatedParameters(column.Parameters, keys, columnAsset, action == utils.AssetStateClear, syncedAt); ok {
+			updatedColumns[i].Parameters = parameters
+			shouldBeUpdated = true
+		}
+	}
+	return updatedColumns, shouldBeUpdated
+}
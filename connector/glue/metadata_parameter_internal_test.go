@@ -0,0 +1,121 @@
+package glue
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testSyncedAt = time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+
+func TestGenMetadataUpdatedParameters(t *testing.T) {
+	keys := NewMetadataParameterKeys(true, "", "", "")
+	asset := qdc.Data{ID: "tbl-1", LogicalName: "顧客"}
+	testCases := []struct {
+		name       string
+		keys       MetadataParameterKeys
+		parameters map[string]string
+		asset      qdc.Data
+		clear      bool
+		want       map[string]string
+		wantUpdate bool
+	}{
+		{
+			name:       "disabled",
+			keys:       MetadataParameterKeys{},
+			parameters: map[string]string{"classification": "parquet"},
+			asset:      asset,
+			want:       map[string]string{"classification": "parquet"},
+			wantUpdate: false,
+		},
+		{
+			name:       "add",
+			keys:       keys,
+			parameters: map[string]string{"classification": "parquet"},
+			asset:      asset,
+			want:       map[string]string{"classification": "parquet", "qdic_logical_name": "顧客", "qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-04-01T09:00:00Z"},
+			wantUpdate: true,
+		},
+		{
+			name:       "no change keeps the sync time",
+			keys:       keys,
+			parameters: map[string]string{"qdic_logical_name": "顧客", "qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-01-01T00:00:00Z"},
+			asset:      asset,
+			want:       map[string]string{"qdic_logical_name": "顧客", "qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-01-01T00:00:00Z"},
+			wantUpdate: false,
+		},
+		{
+			name:       "logical name removed in qdic",
+			keys:       keys,
+			parameters: map[string]string{"qdic_logical_name": "顧客", "qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-01-01T00:00:00Z"},
+			asset:      qdc.Data{ID: "tbl-1"},
+			want:       map[string]string{"qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-04-01T09:00:00Z"},
+			wantUpdate: true,
+		},
+		{
+			name:       "clear",
+			keys:       keys,
+			parameters: map[string]string{"classification": "parquet", "qdic_logical_name": "顧客", "qdic_asset_id": "tbl-1", "qdic_synced_at": "2024-01-01T00:00:00Z"},
+			asset:      asset,
+			clear:      true,
+			want:       map[string]string{"classification": "parquet"},
+			wantUpdate: true,
+		},
+		{
+			name:       "clear without parameters",
+			keys:       keys,
+			parameters: nil,
+			asset:      asset,
+			clear:      true,
+			want:       nil,
+			wantUpdate: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, updated := genMetadataUpdatedParameters(tc.parameters, tc.keys, tc.asset, tc.clear, testSyncedAt)
+			if updated != tc.wantUpdate {
+				t.Errorf("want %v but got %v", tc.wantUpdate, updated)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parameters mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenMetadataUpdatedColumns(t *testing.T) {
+	keys := NewMetadataParameterKeys(true, "logical_name", "asset_id", "synced_at")
+	columns := []types.Column{
+		{Name: aws.String("id"), Type: aws.String("int")},
+		{Name: aws.String("name"), Type: aws.String("string"), Parameters: map[string]string{"logical_name": "氏名", "asset_id": "col-2"}},
+		{Name: aws.String("lost"), Type: aws.String("string")},
+		{Name: aws.String("unknown"), Type: aws.String("string")},
+	}
+	columnAssets := []qdc.Data{
+		{ID: "col-1", PhysicalName: "id", LogicalName: "ID"},
+		{ID: "col-2", PhysicalName: "name", LogicalName: "氏名"},
+		{ID: "col-3", PhysicalName: "lost", LogicalName: "消失", IsLost: true},
+	}
+	want := []types.Column{
+		{Name: aws.String("id"), Type: aws.String("int"), Parameters: map[string]string{"logical_name": "ID", "asset_id": "col-1", "synced_at": "2024-04-01T09:00:00Z"}},
+		{Name: aws.String("name"), Type: aws.String("string"), Parameters: map[string]string{"logical_name": "氏名", "asset_id": "col-2"}},
+		{Name: aws.String("lost"), Type: aws.String("string")},
+		{Name: aws.String("unknown"), Type: aws.String("string")},
+	}
+	got, updated := genMetadataUpdatedColumns(keys, utils.DefaultAssetStatePolicy(), columns, columnAssets, testSyncedAt)
+	if !updated {
+		t.Errorf("columns should be updated")
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(types.Column{})); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	if columns[0].Parameters != nil {
+		t.Errorf("the original columns should not be changed")
+	}
+}
@@ -0,0 +1,106 @@
+package glue
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+const (
+	TableFormatHive    = "HIVE"
+	TableFormatIceberg = "ICEBERG"
+	TableFormatDelta   = "DELTA"
+	TableFormatHudi    = "HUDI"
+)
+
+// metadataPointerParameterKeys are the parameters that point to the current metadata of the open table formats.
+// They are owned by the writers of the tables, so the agent never changes them.
+var metadataPointerParameterKeys = []string{
+	"metadata_location",
+	"previous_metadata_location",
+	"spark.sql.sources.provider",
+	"spark.sql.sources.schema",
+	"spark.sql.sources.schema.numParts",
+	"table_type",
+}
+
+// detectTableFormat returns the format of the table from its parameters and input format.
+func detectTableFormat(glueTable *types.Table) string {
+	if glueTable == nil {
+		return TableFormatHive
+	}
+	switch strings.ToUpper(glueTable.Parameters["table_type"]) {
+	case TableFormatIceberg:
+		return TableFormatIceberg
+	case TableFormatDelta:
+		return TableFormatDelta
+	case TableFormatHudi:
+		return TableFormatHudi
+	}
+	switch strings.ToLower(glueTable.Parameters["spark.sql.sources.provider"]) {
+	case "delta":
+		return TableFormatDelta
+	case "hudi":
+		return TableFormatHudi
+	}
+	if glueTable.StorageDescriptor != nil && strings.Contains(strings.ToLower(aws.ToString(glueTable.StorageDescriptor.InputFormat)), "hudi") {
+		return TableFormatHudi
+	}
+	return TableFormatHive
+}
+
+func isOpenTableFormat(format string) bool {
+	return format != TableFormatHive
+}
+
+// checkOpenTableFormat returns the reasons why the table or its columns can't be updated safely.
+// Empty reasons mean that the update is safe.
+func checkOpenTableFormat(format string, glueTable *types.Table) (skipTableReason, skipColumnReason string) {
+	switch format {
+	case TableFormatIceberg:
+		if glueTable.Parameters["metadata_location"] == "" {
+			return "The Iceberg table doesn't have metadata_location.", ""
+		}
+	case TableFormatDelta:
+		if hasPlaceholderColumns(glueTable) {
+			return "", "The columns in Glue are placeholders and the schema is kept in the Delta log."
+		}
+	}
+	return "", ""
+}
+
+// hasPlaceholderColumns returns true when the table has only the dummy column that Spark writes for Delta tables.
+func hasPlaceholderColumns(glueTable *types.Table) bool {
+	if glueTable.StorageDescriptor == nil || len(glueTable.StorageDescriptor.Columns) != 1 {
+		return false
+	}
+	column := glueTable.StorageDescriptor.Columns[0]
+	return aws.ToString(column.Name) == "col" && aws.ToString(column.Type) == "array<string>"
+}
+
+// applyOpenTableFormatSafety makes the update of the open table format safe.
+// The metadata pointers are restored from the table that was read, and the version ID is set, so that
+// UpdateTable fails instead of overwriting the commit of the writer that happened in the meantime.
+func applyOpenTableFormatSafety(updateTableInput *glueService.UpdateTableInput, glueTable *types.Table) error {
+	if glueTable.VersionId == nil {
+		return fmt.Errorf("the table doesn't have VersionId")
+	}
+	updateTableInput.VersionId = glueTable.VersionId
+	parameters := make(map[string]string)
+	for k, v := range updateTableInput.TableInput.Parameters {
+		parameters[k] = v
+	}
+	for _, key := range metadataPointerParameterKeys {
+		if value, ok := glueTable.Parameters[key]; ok {
+			parameters[key] = value
+		} else {
+			delete(parameters, key)
+		}
+	}
+	updateTableInput.TableInput.Parameters = parameters
+	updateTableInput.TableInput.TableType = glueTable.TableType
+	return nil
+}
@@ -0,0 +1,110 @@
+package glue
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectTableFormat(t *testing.T) {
+	testCases := []struct {
+		name  string
+		table *types.Table
+		want  string
+	}{
+		{name: "nil", table: nil, want: TableFormatHive},
+		{name: "hive", table: &types.Table{Parameters: map[string]string{"classification": "parquet"}}, want: TableFormatHive},
+		{name: "iceberg", table: &types.Table{Parameters: map[string]string{"table_type": "iceberg"}}, want: TableFormatIceberg},
+		{name: "delta by table_type", table: &types.Table{Parameters: map[string]string{"table_type": "DELTA"}}, want: TableFormatDelta},
+		{name: "delta by provider", table: &types.Table{Parameters: map[string]string{"spark.sql.sources.provider": "delta"}}, want: TableFormatDelta},
+		{name: "hudi by input format", table: &types.Table{StorageDescriptor: &types.StorageDescriptor{InputFormat: aws.String("org.apache.hudi.hadoop.HoodieParquetInputFormat")}}, want: TableFormatHudi},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectTableFormat(tc.table); got != tc.want {
+				t.Errorf("want %s but got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckOpenTableFormat(t *testing.T) {
+	testCases := []struct {
+		name           string
+		format         string
+		table          *types.Table
+		wantSkipTable  bool
+		wantSkipColumn bool
+	}{
+		{name: "iceberg", format: TableFormatIceberg, table: &types.Table{Parameters: map[string]string{"table_type": "ICEBERG", "metadata_location": "s3://bucket/metadata/00001.metadata.json"}}},
+		{name: "iceberg without metadata location", format: TableFormatIceberg, table: &types.Table{Parameters: map[string]string{"table_type": "ICEBERG"}}, wantSkipTable: true},
+		{
+			name:           "delta with placeholder columns",
+			format:         TableFormatDelta,
+			table:          &types.Table{StorageDescriptor: &types.StorageDescriptor{Columns: []types.Column{{Name: aws.String("col"), Type: aws.String("array<string>")}}}},
+			wantSkipColumn: true,
+		},
+		{
+			name:   "delta with columns",
+			format: TableFormatDelta,
+			table:  &types.Table{StorageDescriptor: &types.StorageDescriptor{Columns: []types.Column{{Name: aws.String("id"), Type: aws.String("int")}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			skipTableReason, skipColumnReason := checkOpenTableFormat(tc.format, tc.table)
+			if (skipTableReason != "") != tc.wantSkipTable {
+				t.Errorf("unexpected skipTableReason: %q", skipTableReason)
+			}
+			if (skipColumnReason != "") != tc.wantSkipColumn {
+				t.Errorf("unexpected skipColumnReason: %q", skipColumnReason)
+			}
+		})
+	}
+}
+
+func TestApplyOpenTableFormatSafety(t *testing.T) {
+	glueTable := &types.Table{
+		Name:      aws.String("orders"),
+		TableType: aws.String("EXTERNAL_TABLE"),
+		VersionId: aws.String("12"),
+		Parameters: map[string]string{
+			"table_type":                 "ICEBERG",
+			"metadata_location":          "s3://bucket/metadata/00012.metadata.json",
+			"previous_metadata_location": "s3://bucket/metadata/00011.metadata.json",
+		},
+	}
+	updateTableInput := glueService.UpdateTableInput{
+		TableInput: &types.TableInput{
+			Name: aws.String("orders"),
+			Parameters: map[string]string{
+				"table_type":      "ICEBERG",
+				"comment":         "【QDIC】orders",
+				"qdic_deprecated": "true",
+			},
+		},
+	}
+	if err := applyOpenTableFormatSafety(&updateTableInput, glueTable); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantParameters := map[string]string{
+		"table_type":                 "ICEBERG",
+		"metadata_location":          "s3://bucket/metadata/00012.metadata.json",
+		"previous_metadata_location": "s3://bucket/metadata/00011.metadata.json",
+		"comment":                    "【QDIC】orders",
+		"qdic_deprecated":            "true",
+	}
+	if diff := cmp.Diff(wantParameters, updateTableInput.TableInput.Parameters); diff != "" {
+		t.Errorf("parameters mismatch (-want +got):\n%s", diff)
+	}
+	if aws.ToString(updateTableInput.VersionId) != "12" || aws.ToString(updateTableInput.TableInput.TableType) != "EXTERNAL_TABLE" {
+		t.Errorf("VersionId and TableType should be taken from the table")
+	}
+
+	if err := applyOpenTableFormatSafety(&glueService.UpdateTableInput{TableInput: &types.TableInput{}}, &types.Table{}); err == nil {
+		t.Errorf("want an error without VersionId")
+	}
+}
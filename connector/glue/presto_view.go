@@ -0,0 +1,241 @@
+package glue
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+// Athena stores the definition of a view in ViewOriginalText as `/* Presto View: <base64 encoded JSON> */`.
+// Athena shows the columns in the JSON, so the column comments have to be written into it as well as StorageDescriptor.
+const (
+	virtualViewTableType = "VIRTUAL_VIEW"
+	prestoViewPrefix     = "/* Presto View: "
+	prestoViewSuffix     = " */"
+)
+
+func isPrestoView(glueTable *types.Table) bool {
+	if glueTable == nil || aws.ToString(glueTable.TableType) != virtualViewTableType {
+		return false
+	}
+	return strings.HasPrefix(aws.ToString(glueTable.ViewOriginalText), prestoViewPrefix)
+}
+
+func decodePrestoView(viewOriginalText string) ([]byte, error) {
+	if !strings.HasPrefix(viewOriginalText, prestoViewPrefix) || !strings.HasSuffix(viewOriginalText, prestoViewSuffix) {
+		return nil, fmt.Errorf("the view original text is not a Presto view")
+	}
+	encoded := strings.TrimSuffix(strings.TrimPrefix(viewOriginalText, prestoViewPrefix), prestoViewSuffix)
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func encodePrestoView(viewJSON []byte) string {
+	return prestoViewPrefix + base64.StdEncoding.EncodeToString(viewJSON) + prestoViewSuffix
+}
+
+// orderedField is a member of a JSON object. The value is kept as it is, so that the fields which the agent
+// doesn't know are written back without any change.
+type orderedField struct {
+	Key   string
+	Value json.RawMessage
+}
+
+type orderedObject []orderedField
+
+func parseOrderedObject(data []byte) (orderedObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("the value is not a JSON object")
+	}
+	var object orderedObject
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("the key of the JSON object is not a string")
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		object = append(object, orderedField{Key: key, Value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (o orderedObject) get(key string) (json.RawMessage, bool) {
+	for _, field := range o {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the value of the key, or appends the key at the end of the object.
+func (o orderedObject) set(key string, value json.RawMessage) orderedObject {
+	for i, field := range o {
+		if field.Key == key {
+			o[i].Value = value
+			return o
+		}
+	}
+	return append(o, orderedField{Key: key, Value: value})
+}
+
+func (o orderedObject) remove(key string) orderedObject {
+	var object orderedObject
+	for _, field := range o {
+		if field.Key != key {
+			object = append(object, field)
+		}
+	}
+	return object
+}
+
+func (o orderedObject) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshalWithoutHTMLEscape(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(field.Value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalWithoutHTMLEscape encodes the value without escaping `<`, `>` and `&`, which are common in SQL.
+func marshalWithoutHTMLEscape(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// genCommentUpdatedPrestoView returns the view original text where the comments of the columns are replaced.
+// The comments map the column names to the comments. The columns that are not in the map are left as they are,
+// and an empty comment removes the comment of the column. The rest of the definition is kept byte for byte.
+// The second return value is false when the view doesn't have to be changed.
+func genCommentUpdatedPrestoView(viewOriginalText string, comments map[string]string) (string, bool, error) {
+	viewJSON, err := decodePrestoView(viewOriginalText)
+	if err != nil {
+		return viewOriginalText, false, err
+	}
+	view, err := parseOrderedObject(viewJSON)
+	if err != nil {
+		return viewOriginalText, false, err
+	}
+	rawColumns, ok := view.get("columns")
+	if !ok {
+		return viewOriginalText, false, nil
+	}
+	var columns []json.RawMessage
+	if err := json.Unmarshal(rawColumns, &columns); err != nil {
+		return viewOriginalText, false, err
+	}
+	shouldBeUpdated := false
+	for i, rawColumn := range columns {
+		column, err := parseOrderedObject(rawColumn)
+		if err != nil {
+			return viewOriginalText, false, err
+		}
+		var name string
+		if rawName, ok := column.get("name"); ok {
+			if err := json.Unmarshal(rawName, &name); err != nil {
+				return viewOriginalText, false, err
+			}
+		}
+		comment, ok := comments[name]
+		if !ok {
+			continue
+		}
+		var currentComment string
+		rawComment, hasComment := column.get("comment")
+		if hasComment {
+			// MEMO: The comment can be null. It's treated as an empty comment.
+			_ = json.Unmarshal(rawComment, &currentComment)
+		}
+		switch {
+		case comment == "" && !hasComment:
+			continue
+		case comment == "":
+			column = column.remove("comment")
+		case hasComment && currentComment == comment:
+			continue
+		default:
+			value, err := marshalWithoutHTMLEscape(comment)
+			if err != nil {
+				return viewOriginalText, false, err
+			}
+			column = column.set("comment", value)
+		}
+		updatedColumn, err := column.marshal()
+		if err != nil {
+			return viewOriginalText, false, err
+		}
+		columns[i] = updatedColumn
+		shouldBeUpdated = true
+	}
+	if !shouldBeUpdated {
+		return viewOriginalText, false, nil
+	}
+	updatedViewJSON, err := view.set("columns", marshalRawMessages(columns)).marshal()
+	if err != nil {
+		return viewOriginalText, false, err
+	}
+	return encodePrestoView(updatedViewJSON), true, nil
+}
+
+func marshalRawMessages(values []json.RawMessage) json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// genViewColumnComments returns the comments of the columns to be written into the Presto view.
+// Only the comments written by the agent and the cleared comments are returned, so that the other comments in the view are kept.
+func genViewColumnComments(prefixForUpdate string, columns []types.Column) map[string]string {
+	comments := make(map[string]string)
+	for _, column := range columns {
+		if column.Name == nil || column.Comment == nil {
+			continue
+		}
+		if *column.Comment == "" || strings.HasPrefix(*column.Comment, prefixForUpdate) {
+			comments[*column.Name] = *column.Comment
+		}
+	}
+	return comments
+}
@@ -0,0 +1,118 @@
+package glue
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testPrestoViewJSON = `{"originalSql":"SELECT id, name FROM customers WHERE score > 0 AND flag <> 'x' & 1","catalog":"awsdatacatalog","schema":"sales","columns":[{"name":"id","type":"integer"},{"name":"name","type":"varchar","comment":"user comment"},{"name":"score","type":"double","comment":"【QDIC】old"}],"owner":"arn:aws:iam::123456789012:user/someone","runAsInvoker":false,"properties":{}}`
+
+func TestPrestoViewRoundTrip(t *testing.T) {
+	viewOriginalText := encodePrestoView([]byte(testPrestoViewJSON))
+	decoded, err := decodePrestoView(viewOriginalText)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(testPrestoViewJSON, string(decoded)); diff != "" {
+		t.Errorf("decoded view mismatch (-want +got):\n%s", diff)
+	}
+	object, err := parseOrderedObject(decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	marshaled, err := object.marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(testPrestoViewJSON, string(marshaled)); diff != "" {
+		t.Errorf("re-encoded view mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenCommentUpdatedPrestoView(t *testing.T) {
+	viewOriginalText := encodePrestoView([]byte(testPrestoViewJSON))
+	testCases := []struct {
+		name       string
+		comments   map[string]string
+		wantJSON   string
+		wantUpdate bool
+	}{
+		{
+			name:       "no comments",
+			comments:   map[string]string{},
+			wantJSON:   testPrestoViewJSON,
+			wantUpdate: false,
+		},
+		{
+			name:       "same comment",
+			comments:   map[string]string{"score": "【QDIC】old"},
+			wantJSON:   testPrestoViewJSON,
+			wantUpdate: false,
+		},
+		{
+			name:       "add and replace",
+			comments:   map[string]string{"id": "【QDIC】顧客ID <PK>", "score": "【QDIC】new"},
+			wantJSON:   `{"originalSql":"SELECT id, name FROM customers WHERE score > 0 AND flag <> 'x' & 1","catalog":"awsdatacatalog","schema":"sales","columns":[{"name":"id","type":"integer","comment":"【QDIC】顧客ID <PK>"},{"name":"name","type":"varchar","comment":"user comment"},{"name":"score","type":"double","comment":"【QDIC】new"}],"owner":"arn:aws:iam::123456789012:user/someone","runAsInvoker":false,"properties":{}}`,
+			wantUpdate: true,
+		},
+		{
+			name:       "clear",
+			comments:   map[string]string{"score": "", "id": ""},
+			wantJSON:   `{"originalSql":"SELECT id, name FROM customers WHERE score > 0 AND flag <> 'x' & 1","catalog":"awsdatacatalog","schema":"sales","columns":[{"name":"id","type":"integer"},{"name":"name","type":"varchar","comment":"user comment"},{"name":"score","type":"double"}],"owner":"arn:aws:iam::123456789012:user/someone","runAsInvoker":false,"properties":{}}`,
+			wantUpdate: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, updated, err := genCommentUpdatedPrestoView(viewOriginalText, tc.comments)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if updated != tc.wantUpdate {
+				t.Errorf("want %v but got %v", tc.wantUpdate, updated)
+			}
+			gotJSON, err := decodePrestoView(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantJSON, string(gotJSON)); diff != "" {
+				t.Errorf("view mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenCommentUpdatedPrestoViewInvalid(t *testing.T) {
+	for _, viewOriginalText := range []string{"SELECT 1", prestoViewPrefix + "not base64" + prestoViewSuffix, encodePrestoView([]byte("[]"))} {
+		got, updated, err := genCommentUpdatedPrestoView(viewOriginalText, map[string]string{"id": "comment"})
+		if err == nil || updated || got != viewOriginalText {
+			t.Errorf("want an error and no change for %q", viewOriginalText)
+		}
+	}
+}
+
+func TestIsPrestoView(t *testing.T) {
+	view := &types.Table{TableType: aws.String("VIRTUAL_VIEW"), ViewOriginalText: aws.String(encodePrestoView([]byte(testPrestoViewJSON)))}
+	if !isPrestoView(view) {
+		t.Errorf("want a Presto view")
+	}
+	if isPrestoView(&types.Table{TableType: aws.String("EXTERNAL_TABLE")}) {
+		t.Errorf("want not a Presto view")
+	}
+}
+
+func TestGenViewColumnComments(t *testing.T) {
+	columns := []types.Column{
+		{Name: aws.String("id"), Comment: aws.String("【QDIC】ID")},
+		{Name: aws.String("name"), Comment: aws.String("user comment")},
+		{Name: aws.String("score"), Comment: aws.String("")},
+		{Name: aws.String("flag")},
+	}
+	want := map[string]string{"id": "【QDIC】ID", "score": ""}
+	if diff := cmp.Diff(want, genViewColumnComments("【QDIC】", columns)); diff != "" {
+		t.Errorf("comments mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,188 @@
+package glue
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/glue"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+const (
+	// ResourceLinkSkip leaves resource links and the shared objects as they are, and reports them.
+	ResourceLinkSkip = "SKIP"
+	// ResourceLinkFollow updates the shared objects in the catalog of the owning account.
+	ResourceLinkFollow = "FOLLOW"
+)
+
+func parseResourceLinkPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return ResourceLinkSkip, nil
+	case ResourceLinkSkip, ResourceLinkFollow:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid resource link policy %s. It must be %s or %s", policy, ResourceLinkSkip, ResourceLinkFollow)
+	}
+}
+
+// parseAccountRoles parses the IAM roles for the accounts that own the shared objects.
+// The format is `<account ID>=<role ARN>` separated by white spaces.
+func parseAccountRoles(accountRoles string) (map[string]string, error) {
+	roles := make(map[string]string)
+	for _, accountRole := range utils.ConvertStringToListByWhiteSpace(accountRoles) {
+		accountID, roleARN, ok := strings.Cut(accountRole, "=")
+		if !ok || accountID == "" || roleARN == "" {
+			return nil, fmt.Errorf("invalid account role %s. It must be <account ID>=<role ARN>", accountRole)
+		}
+		roles[accountID] = roleARN
+	}
+	return roles, nil
+}
+
+// GlueRepoKey identifies the client for the catalog of the account in the region.
+type GlueRepoKey struct {
+	AccountID string
+	Region    string
+}
+
+// genTargetGlueRepos returns the clients for the catalogs that the resource links of the catalog target refer to.
+// They are the clients of the accounts with roles in the region of the target, and the clients of all of the catalog targets.
+// The clients are shared among the catalog targets through cache.
+func genTargetGlueRepos(target CatalogTarget, targets []CatalogTarget, accountRoles map[string]string, profileName string, cache map[GlueRepoKey]glue.GlueClient) (map[GlueRepoKey]glue.GlueClient, error) {
+	repos := make(map[GlueRepoKey]glue.GlueClient)
+	for accountID, roleARN := range accountRoles {
+		key := GlueRepoKey{AccountID: accountID, Region: target.Region}
+		repo, ok := cache[key]
+		if !ok {
+			var err error
+			repo, err = glue.NewGlueClient(roleARN, profileName, target.Region)
+			if err != nil {
+				return nil, err
+			}
+			cache[key] = repo
+		}
+		repos[key] = repo
+	}
+	// MEMO: The catalog targets can own the objects shared with the other targets, even in the other regions.
+	for _, catalogTarget := range targets {
+		repos[GlueRepoKey{AccountID: catalogTarget.AccountID, Region: catalogTarget.Region}] = catalogTarget.glueRepo
+	}
+	return repos, nil
+}
+
+// glueRepoForCatalog returns the client for the catalog in the region. The region of the default client is used if region is empty.
+// The default client is used for the catalogs without client.
+func (g *GlueConnector) glueRepoForCatalog(catalogID, region string) *glue.GlueClient {
+	if region == "" {
+		region = g.GlueRepo.Region
+	}
+	if repo, ok := g.TargetGlueRepos[GlueRepoKey{AccountID: catalogID, Region: region}]; ok {
+		return &repo
+	}
+	return &g.GlueRepo
+}
+
+// getDatabaseLinkTarget returns the shared database that the database refers to.
+// nil is returned for the databases in the account.
+func getDatabaseLinkTarget(accountID string, glueDB types.Database) *types.DatabaseIdentifier {
+	if glueDB.TargetDatabase != nil {
+		return glueDB.TargetDatabase
+	}
+	if glueDB.CatalogId != nil && *glueDB.CatalogId != accountID {
+		return &types.DatabaseIdentifier{
+			CatalogId:    glueDB.CatalogId,
+			DatabaseName: glueDB.Name,
+		}
+	}
+	return nil
+}
+
+// getTableLinkTarget returns the shared table that the table refers to. A table read through a resource link
+// of a database belongs to the catalog of the owning account. nil is returned for the tables in the account.
+func getTableLinkTarget(accountID string, glueTable *types.Table) *types.TableIdentifier {
+	if glueTable == nil {
+		return nil
+	}
+	if glueTable.TargetTable != nil {
+		return glueTable.TargetTable
+	}
+	if glueTable.CatalogId != nil && *glueTable.CatalogId != accountID {
+		return &types.TableIdentifier{
+			CatalogId:    glueTable.CatalogId,
+			DatabaseName: glueTable.DatabaseName,
+			Name:         glueTable.Name,
+		}
+	}
+	return nil
+}
+
+// checkResourceLink returns the reason why the shared object isn't updated. Empty reason means that it can be followed.
+func (g *GlueConnector) checkResourceLink(catalogID, region string) string {
+	if g.ResourceLinkPolicy != ResourceLinkFollow {
+		return "Resource links are not followed."
+	}
+	repo := g.glueRepoForCatalog(catalogID, region)
+	if region != "" && repo.Region != "" && region != repo.Region {
+		return fmt.Sprintf("The shared object is in %s, but the client is for %s.", region, repo.Region)
+	}
+	return ""
+}
+
+// resolveDatabaseLink returns the shared database that the resource link refers to and the client for its catalog.
+// nil is returned when the shared database isn't updated. The reason is reported.
+func (g *GlueConnector) resolveDatabaseLink(dbName string, target *types.DatabaseIdentifier) (*glue.GlueClient, *types.Database, error) {
+	targetName := fmt.Sprintf("%s:%s", aws.ToString(target.CatalogId), aws.ToString(target.DatabaseName))
+	if reason := g.checkResourceLink(aws.ToString(target.CatalogId), aws.ToString(target.Region)); reason != "" {
+		g.Report.Add(report.WARNING, "athena", dbName, "resource_link", fmt.Sprintf("Skipped the resource link to %s. %s", targetName, reason))
+		return nil, nil, nil
+	}
+	repo := g.glueRepoForCatalog(aws.ToString(target.CatalogId), aws.ToString(target.Region))
+	output, err := repo.GetDatabase(aws.ToString(target.CatalogId), aws.ToString(target.DatabaseName))
+	if err != nil {
+		if reason, ok := getSkippableLinkError(err); ok {
+			g.Report.Add(report.WARNING, "athena", dbName, "resource_link", fmt.Sprintf("Skipped the resource link to %s. %s", targetName, reason))
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	g.Logger.Debug("Follow the resource link %s to %s", dbName, targetName)
+	return repo, output.Database, nil
+}
+
+// resolveTableLink returns the shared table that the table refers to and the client for its catalog.
+// nil is returned when the shared table isn't updated. The reason is reported.
+func (g *GlueConnector) resolveTableLink(tableFQN string, target *types.TableIdentifier) (*glue.GlueClient, *glueService.GetTableOutput, error) {
+	targetName := fmt.Sprintf("%s:%s.%s", aws.ToString(target.CatalogId), aws.ToString(target.DatabaseName), aws.ToString(target.Name))
+	if reason := g.checkResourceLink(aws.ToString(target.CatalogId), aws.ToString(target.Region)); reason != "" {
+		g.Report.Add(report.WARNING, "athena", tableFQN, "resource_link", fmt.Sprintf("Skipped the resource link to %s. %s", targetName, reason))
+		return nil, nil, nil
+	}
+	repo := g.glueRepoForCatalog(aws.ToString(target.CatalogId), aws.ToString(target.Region))
+	output, err := repo.GetTable(aws.ToString(target.CatalogId), aws.ToString(target.DatabaseName), aws.ToString(target.Name))
+	if err != nil {
+		if reason, ok := getSkippableLinkError(err); ok {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "resource_link", fmt.Sprintf("Skipped the resource link to %s. %s", targetName, reason))
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	g.Logger.Debug("Follow the resource link %s to %s", tableFQN, targetName)
+	return repo, output, nil
+}
+
+func getSkippableLinkError(err error) (string, bool) {
+	switch code.CategoryOf(err) {
+	case code.NotFound:
+		return "The shared object is not found.", true
+	case code.Permission:
+		return "The role is not authorized to read the shared object.", true
+	default:
+		return "", false
+	}
+}
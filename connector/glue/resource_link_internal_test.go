@@ -0,0 +1,129 @@
+package glue
+
+import (
+	"quollio-reverse-agent/repository/glue"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseAccountRoles(t *testing.T) {
+	roles, err := parseAccountRoles("111111111111=arn:aws:iam::111111111111:role/a  222222222222=arn:aws:iam::222222222222:role/b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]string{
+		"111111111111": "arn:aws:iam::111111111111:role/a",
+		"222222222222": "arn:aws:iam::222222222222:role/b",
+	}
+	if diff := cmp.Diff(want, roles); diff != "" {
+		t.Errorf("roles mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := parseAccountRoles("111111111111"); err == nil {
+		t.Errorf("want an error without role ARN")
+	}
+}
+
+func TestParseResourceLinkPolicy(t *testing.T) {
+	if policy, err := parseResourceLinkPolicy(""); err != nil || policy != ResourceLinkSkip {
+		t.Errorf("want %s by default but got %s", ResourceLinkSkip, policy)
+	}
+	if _, err := parseResourceLinkPolicy("UPDATE"); err == nil {
+		t.Errorf("want an error for an invalid policy")
+	}
+}
+
+func TestGetDatabaseLinkTarget(t *testing.T) {
+	link := &types.DatabaseIdentifier{CatalogId: aws.String("222222222222"), DatabaseName: aws.String("sales")}
+	testCases := []struct {
+		name string
+		db   types.Database
+		want *types.DatabaseIdentifier
+	}{
+		{name: "local", db: types.Database{Name: aws.String("local"), CatalogId: aws.String("111111111111")}, want: nil},
+		{name: "resource link", db: types.Database{Name: aws.String("sales_link"), CatalogId: aws.String("111111111111"), TargetDatabase: link}, want: link},
+		{name: "shared", db: types.Database{Name: aws.String("sales"), CatalogId: aws.String("222222222222")}, want: link},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getDatabaseLinkTarget("111111111111", tc.db)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(types.DatabaseIdentifier{})); diff != "" {
+				t.Errorf("target mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetTableLinkTarget(t *testing.T) {
+	link := &types.TableIdentifier{CatalogId: aws.String("222222222222"), DatabaseName: aws.String("sales"), Name: aws.String("orders")}
+	testCases := []struct {
+		name  string
+		table *types.Table
+		want  *types.TableIdentifier
+	}{
+		{name: "nil", table: nil, want: nil},
+		{name: "local", table: &types.Table{Name: aws.String("orders"), CatalogId: aws.String("111111111111")}, want: nil},
+		{name: "resource link", table: &types.Table{Name: aws.String("orders_link"), CatalogId: aws.String("111111111111"), TargetTable: link}, want: link},
+		{name: "through a linked database", table: &types.Table{Name: aws.String("orders"), DatabaseName: aws.String("sales"), CatalogId: aws.String("222222222222")}, want: link},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getTableLinkTarget("111111111111", tc.table)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(types.TableIdentifier{})); diff != "" {
+				t.Errorf("target mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckResourceLink(t *testing.T) {
+	connector := GlueConnector{
+		GlueRepo:           glue.GlueClient{Region: "ap-northeast-1"},
+		ResourceLinkPolicy: ResourceLinkFollow,
+		TargetGlueRepos: map[GlueRepoKey]glue.GlueClient{
+			{AccountID: "222222222222", Region: "us-east-1"}: {Region: "us-east-1"},
+			{AccountID: "222222222222", Region: "eu-west-1"}: {Region: "eu-west-1"},
+		},
+	}
+	if reason := connector.checkResourceLink("333333333333", "ap-northeast-1"); reason != "" {
+		t.Errorf("want no reason but got %s", reason)
+	}
+	if reason := connector.checkResourceLink("222222222222", "us-east-1"); reason != "" {
+		t.Errorf("want no reason but got %s", reason)
+	}
+	if reason := connector.checkResourceLink("333333333333", "us-east-1"); reason == "" {
+		t.Errorf("want a reason for the other region")
+	}
+	if reason := connector.checkResourceLink("222222222222", "eu-west-1"); reason != "" {
+		t.Errorf("want no reason for the client in the region but got %s", reason)
+	}
+	if reason := connector.checkResourceLink("222222222222", "ap-southeast-1"); reason == "" {
+		t.Errorf("want a reason for the region without client")
+	}
+	connector.ResourceLinkPolicy = ResourceLinkSkip
+	if reason := connector.checkResourceLink("333333333333", ""); reason == "" {
+		t.Errorf("want a reason for the skip policy")
+	}
+}
+
+func TestWithCatalogTargetSwitchesTargetGlueRepos(t *testing.T) {
+	targetRepos := map[GlueRepoKey]glue.GlueClient{
+		{AccountID: "222222222222", Region: "us-east-1"}: {Region: "us-east-1"},
+	}
+	connector := GlueConnector{
+		GlueRepo:           glue.GlueClient{Region: "ap-northeast-1"},
+		ResourceLinkPolicy: ResourceLinkFollow,
+		TargetGlueRepos:    map[GlueRepoKey]glue.GlueClient{},
+	}
+	target := CatalogTarget{AccountID: "111111111111", Region: "us-east-1", glueRepo: glue.GlueClient{Region: "us-east-1"}, targetGlueRepos: targetRepos}
+	catalogConnector := connector.withCatalogTarget(target)
+	if got := catalogConnector.glueRepoForCatalog("222222222222", "").Region; got != "us-east-1" {
+		t.Errorf("want the client in us-east-1 but got %s", got)
+	}
+	if reason := catalogConnector.checkResourceLink("222222222222", "us-east-1"); reason != "" {
+		t.Errorf("want no reason but got %s", reason)
+	}
+}
@@ -0,0 +1,44 @@
+package glue
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+// tableCommentParameterKey is the parameter that Hive, Spark and SHOW CREATE TABLE of Athena read as the table comment.
+const tableCommentParameterKey = "comment"
+
+// isForeignTableComment returns true when Parameters["comment"] has a value that differs from the description
+// and was not written by the agent.
+func isForeignTableComment(prefixForUpdate string, glueTable *types.Table) bool {
+	if glueTable == nil {
+		return false
+	}
+	comment := glueTable.Parameters[tableCommentParameterKey]
+	if comment == "" || comment == aws.ToString(glueTable.Description) {
+		return false
+	}
+	return !strings.HasPrefix(comment, prefixForUpdate)
+}
+
+// genCommentUpdatedParameters returns a copy of the parameters where the comment is set to the description.
+// The comment is removed when the description is empty.
+// The second return value is false when the parameters don't have to be changed.
+func genCommentUpdatedParameters(parameters map[string]string, desc string) (map[string]string, bool) {
+	current, hasKey := parameters[tableCommentParameterKey]
+	if (desc == "" && !hasKey) || (desc != "" && current == desc) {
+		return parameters, false
+	}
+	updatedParameters := make(map[string]string)
+	for k, v := range parameters {
+		updatedParameters[k] = v
+	}
+	if desc == "" {
+		delete(updatedParameters, tableCommentParameterKey)
+	} else {
+		updatedParameters[tableCommentParameterKey] = desc
+	}
+	return updatedParameters, true
+}
@@ -0,0 +1,57 @@
+package glue
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsForeignTableComment(t *testing.T) {
+	testCases := []struct {
+		name  string
+		table *types.Table
+		want  bool
+	}{
+		{name: "nil table", table: nil, want: false},
+		{name: "no comment", table: &types.Table{Description: aws.String("desc")}, want: false},
+		{name: "same as description", table: &types.Table{Description: aws.String("desc"), Parameters: map[string]string{"comment": "desc"}}, want: false},
+		{name: "written by the agent", table: &types.Table{Description: aws.String("【QDIC】new"), Parameters: map[string]string{"comment": "【QDIC】old"}}, want: false},
+		{name: "written by hive", table: &types.Table{Description: aws.String("【QDIC】desc"), Parameters: map[string]string{"comment": "hive comment"}}, want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isForeignTableComment("【QDIC】", tc.table); got != tc.want {
+				t.Errorf("want %v but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGenCommentUpdatedParameters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters map[string]string
+		desc       string
+		want       map[string]string
+		wantUpdate bool
+	}{
+		{name: "add", parameters: map[string]string{"classification": "csv"}, desc: "【QDIC】desc", want: map[string]string{"classification": "csv", "comment": "【QDIC】desc"}, wantUpdate: true},
+		{name: "same", parameters: map[string]string{"comment": "【QDIC】desc"}, desc: "【QDIC】desc", want: map[string]string{"comment": "【QDIC】desc"}, wantUpdate: false},
+		{name: "replace", parameters: map[string]string{"comment": "【QDIC】old"}, desc: "【QDIC】new", want: map[string]string{"comment": "【QDIC】new"}, wantUpdate: true},
+		{name: "clear", parameters: map[string]string{"classification": "csv", "comment": "【QDIC】old"}, desc: "", want: map[string]string{"classification": "csv"}, wantUpdate: true},
+		{name: "clear without comment", parameters: nil, desc: "", want: nil, wantUpdate: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, updated := genCommentUpdatedParameters(tc.parameters, tc.desc)
+			if updated != tc.wantUpdate {
+				t.Errorf("want %v but got %v", tc.wantUpdate, updated)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parameters mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
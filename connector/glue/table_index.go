@@ -0,0 +1,122 @@
+package glue
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/repository/qdc"
+	"strconv"
+
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+// defaultBulkReadThreshold is the number of the table assets in a database from which the tables are read with GetTables.
+// Glue has no API to read a set of tables by name, so the tables of the databases with fewer assets are read one by one.
+// 0 disables the bulk read.
+const defaultBulkReadThreshold = 10
+
+func parseBulkReadThreshold(threshold string) (int, error) {
+	if threshold == "" {
+		return defaultBulkReadThreshold, nil
+	}
+	value, err := strconv.Atoi(threshold)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid bulk read threshold %s. It must be 0 or a positive integer", threshold)
+	}
+	return value, nil
+}
+
+// tableIndex holds the tables read in bulk by database name and table name.
+type tableIndex struct {
+	tables map[string]map[string]types.Table
+}
+
+func (t tableIndex) isLoaded(dbName string) bool {
+	_, ok := t.tables[dbName]
+	return ok
+}
+
+func (t tableIndex) get(dbName, tableName string) (types.Table, bool) {
+	table, ok := t.tables[dbName][tableName]
+	return table, ok
+}
+
+// countTableAssetsByDatabase returns the number of the table assets in each database.
+func countTableAssetsByDatabase(tableAssets []qdc.Data) map[string]int {
+	counts := make(map[string]int)
+	for _, tableAsset := range tableAssets {
+		databaseAsset := qdc.GetSpecifiedAssetFromPath(tableAsset, "schema3")
+		counts[databaseAsset.Name]++
+	}
+	return counts
+}
+
+// buildTableIndex reads all of the tables in the databases that have at least threshold table assets.
+// A database that isn't found is indexed without tables, so that its tables are handled as not found.
+func (g *GlueConnector) buildTableIndex(tableAssets []qdc.Data, threshold int) (tableIndex, error) {
+	index := tableIndex{tables: make(map[string]map[string]types.Table)}
+	if threshold < 1 {
+		return index, nil
+	}
+	for dbName, count := range countTableAssetsByDatabase(tableAssets) {
+		if count < threshold {
+			continue
+		}
+		tables, err := g.getAllTables(dbName)
+		if err != nil {
+			switch {
+			case code.Is(err, code.NotFound):
+				g.Logger.Warning("Database Not Found in your AWS account. Skip to read the tables. database name: %s", dbName)
+				index.tables[dbName] = map[string]types.Table{}
+			case code.Decide(err) == code.Abort:
+				return tableIndex{}, err
+			default:
+				// MEMO: The tables of the database are read one by one, and the errors are handled for each table.
+				g.Logger.Warning("Failed to read the tables in bulk. database name: %s. %s", dbName, err.Error())
+			}
+			continue
+		}
+		index.tables[dbName] = make(map[string]types.Table)
+		for _, table := range tables {
+			if table.Name != nil {
+				index.tables[dbName][*table.Name] = table
+			}
+		}
+		g.Logger.Debug("Read %d tables in bulk. database name: %s", len(tables), dbName)
+	}
+	return index, nil
+}
+
+func (g *GlueConnector) getAllTables(dbName string) ([]types.Table, error) {
+	var tables []types.Table
+	var nextToken string
+	for {
+		output, err := g.GlueRepo.GetTables(g.AthenaAccountID, dbName, nextToken)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, output.TableList...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			return tables, nil
+		}
+		nextToken = *output.NextToken
+	}
+}
+
+// getTable returns the table from the index if its database was read in bulk, or reads it with GetTable.
+// A table missing from the index returns the same error as GetTable for a table that doesn't exist.
+func (g *GlueConnector) getTable(index tableIndex, dbName, tableName string) (*glueService.GetTableOutput, error) {
+	if !index.isLoaded(dbName) {
+		return g.GlueRepo.GetTable(g.AthenaAccountID, dbName, tableName)
+	}
+	table, ok := index.get(dbName, tableName)
+	if !ok {
+		return nil, &code.Error{
+			Service:   "glue",
+			Operation: "GetTables",
+			Category:  code.NotFound,
+			Message:   fmt.Sprintf("The table %s.%s is not found in the tables read in bulk.", dbName, tableName),
+		}
+	}
+	return &glueService.GetTableOutput{Table: &table}, nil
+}
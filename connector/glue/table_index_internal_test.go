@@ -0,0 +1,56 @@
+package glue
+
+import (
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBulkReadThreshold(t *testing.T) {
+	if threshold, err := parseBulkReadThreshold(""); err != nil || threshold != defaultBulkReadThreshold {
+		t.Errorf("want %d by default but got %d", defaultBulkReadThreshold, threshold)
+	}
+	if threshold, err := parseBulkReadThreshold("0"); err != nil || threshold != 0 {
+		t.Errorf("want 0 to disable the bulk read but got %d", threshold)
+	}
+	for _, threshold := range []string{"-1", "ten"} {
+		if _, err := parseBulkReadThreshold(threshold); err == nil {
+			t.Errorf("want an error for %s", threshold)
+		}
+	}
+}
+
+func TestCountTableAssetsByDatabase(t *testing.T) {
+	genTableAsset := func(dbName string) qdc.Data {
+		return qdc.Data{Path: []qdc.Path{{PathLayer: "schema3", Name: dbName}}}
+	}
+	counts := countTableAssetsByDatabase([]qdc.Data{genTableAsset("sales"), genTableAsset("sales"), genTableAsset("hr")})
+	want := map[string]int{"sales": 2, "hr": 1}
+	if diff := cmp.Diff(want, counts); diff != "" {
+		t.Errorf("counts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetTableFromIndex(t *testing.T) {
+	g := &GlueConnector{}
+	index := tableIndex{tables: map[string]map[string]types.Table{
+		"sales": {"orders": {Name: aws.String("orders"), Description: aws.String("desc")}},
+	}}
+
+	output, err := g.getTable(index, "sales", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if aws.ToString(output.Table.Description) != "desc" {
+		t.Errorf("want the indexed table but got %v", output.Table)
+	}
+
+	_, err = g.getTable(index, "sales", "customers")
+	if !code.Is(err, code.NotFound) {
+		t.Errorf("want %s for the table missing from the index but got %v", code.NotFound, err)
+	}
+}
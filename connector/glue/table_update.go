@@ -0,0 +1,173 @@
+package glue
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/glue"
+	"quollio-reverse-agent/repository/qdc"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+)
+
+// maxRetryAttempts is the number of the attempts of the calls that fail with the errors to be retried.
+const maxRetryAttempts = 3
+
+// retryBackoff is the wait before the first retry. It doubles on every retry.
+var retryBackoff = time.Second
+
+// updateTable updates the table with VersionId, so that the change of a crawler or an ETL job made after the table is read
+// isn't rolled back. On the conflict, the table is read again and the changes of the agent are generated against the new version.
+// It returns the table that was updated last and false if the table is skipped. The reason is reported.
+func (g *GlueConnector) updateTable(glueRepo *glue.GlueClient, glueTable *glueService.GetTableOutput, tableAsset qdc.Data, columnAssets, storageColumnAssets []qdc.Data, action, tableFQN string) (*glueService.GetTableOutput, bool, error) {
+	syncedAt := time.Now()
+	for attempt := 1; ; attempt++ {
+		tableFormat := detectTableFormat(glueTable.Table)
+		updateTableInput, tableShouldBeUpdated, columnShouldBeUpdated := g.genTableUpdate(glueTable, tableAsset, columnAssets, storageColumnAssets, action, tableFQN, syncedAt)
+		if !tableShouldBeUpdated && !columnShouldBeUpdated {
+			return glueTable, true, nil
+		}
+		if g.SkipUnchangedTableUpdate || g.SkipArchiveOnMetadataUpdate {
+			changed, metadataOnly := diffTableInput(*genUpdateTableInput(glueTable).TableInput, *updateTableInput.TableInput)
+			if g.SkipUnchangedTableUpdate && !changed {
+				g.Logger.Debug("Skip table update because the rendered values are the same as the current ones: %s", tableFQN)
+				return glueTable, true, nil
+			}
+			// MEMO: The version isn't archived when only the metadata written by the agent is changed.
+			if g.SkipArchiveOnMetadataUpdate && metadataOnly {
+				updateTableInput.SkipArchive = aws.Bool(true)
+			}
+		}
+		if isOpenTableFormat(tableFormat) {
+			if err := applyOpenTableFormatSafety(&updateTableInput, glueTable.Table); err != nil {
+				g.Report.Add(report.WARNING, "athena", tableFQN, "table_format", fmt.Sprintf("Skipped the %s table. %s", tableFormat, err.Error()))
+				return glueTable, false, nil
+			}
+		} else {
+			updateTableInput.VersionId = glueTable.Table.VersionId
+		}
+		_, err := glueRepo.UpdateTable(aws.ToString(glueTable.Table.CatalogId), aws.ToString(glueTable.Table.DatabaseName), updateTableInput)
+		if err == nil {
+			msg := genUpdateMessage(tableShouldBeUpdated, columnShouldBeUpdated)
+			g.Logger.Debug("Update table. msg: %s table name %s", msg, tableAsset.PhysicalName)
+			if err := g.verifyTableUpdate(glueRepo, glueTable, tableFQN); err != nil {
+				return glueTable, false, err
+			}
+			// MEMO: The versions are pruned only when the agent created a new one.
+			if g.TableVersionRetention > 0 {
+				if err := g.pruneTableVersions(glueRepo, glueTable.Table, tableFQN); err != nil {
+					return glueTable, false, err
+				}
+			}
+			return glueTable, true, nil
+		}
+		if code.Decide(err) != code.Retry {
+			return glueTable, false, g.handleAssetError(tableFQN, "table", err)
+		}
+		if attempt >= maxRetryAttempts {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "version", fmt.Sprintf("Skipped the %s table after %d attempts. %s", tableFormat, attempt, err.Error()))
+			return glueTable, false, nil
+		}
+		// MEMO: The table is read again for any error to be retried, because the conflict may be reported as the other errors.
+		backoff := retryBackoff * time.Duration(1<<(attempt-1))
+		g.Logger.Debug("Failed to update the table with %s. Read it again after %s. table name %s", code.CategoryOf(err), backoff, tableFQN)
+		time.Sleep(backoff)
+		glueTable, err = glueRepo.GetTable(aws.ToString(glueTable.Table.CatalogId), aws.ToString(glueTable.Table.DatabaseName), aws.ToString(glueTable.Table.Name))
+		if err != nil {
+			return nil, false, g.handleAssetError(tableFQN, "table", err)
+		}
+		if skipTableReason, _ := checkOpenTableFormat(detectTableFormat(glueTable.Table), glueTable.Table); skipTableReason != "" {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "table_format", fmt.Sprintf("Skipped the %s table. %s", detectTableFormat(glueTable.Table), skipTableReason))
+			return glueTable, false, nil
+		}
+	}
+}
+
+// genTableUpdate generates the input to update the table with the metadata of QDIC. Everything else in the input is
+// copied from the table, so it must be the version read last. It returns whether the table and the columns are changed.
+func (g *GlueConnector) genTableUpdate(glueTable *glueService.GetTableOutput, tableAsset qdc.Data, columnAssets, storageColumnAssets []qdc.Data, action, tableFQN string, syncedAt time.Time) (glueService.UpdateTableInput, bool, bool) {
+	tableShouldBeUpdated := false
+	updateTableInput := genUpdateTableInput(glueTable)
+	// MEMO: The table comment written outside of the agent is protected like the description.
+	isCommentForeign := g.CommentParameterSyncEnabled && isForeignTableComment(g.PrefixForUpdate, glueTable.Table)
+	if isCommentForeign {
+		g.Report.Add(report.WARNING, "athena", tableFQN, tableCommentParameterKey, "Parameters[\"comment\"] differs from the description and was not written by the agent.")
+	}
+	descriptionShouldBeUpdated := false
+	switch {
+	case isCommentForeign && g.OverwriteMode != utils.OverwriteAll:
+		g.Logger.Debug("Skip table description update because the comment parameter is not owned by the agent: %s", *glueTable.Table.Name)
+	case action == utils.AssetStateUpdate:
+		if shouldTableBeUpdated(g.PrefixForUpdate, g.OverwriteMode, glueTable.Table, tableAsset) {
+			descWithPrefix := utils.RenderDescription(g.PrefixForUpdate, tableAsset.Description, utils.FormatPlainText)
+			descWithPrefix = g.fitDescription(utils.FieldGlueTableDescription, tableFQN, tableAsset.ID, descWithPrefix)
+			g.Logger.Debug("Table will be updated: %s", *glueTable.Table.Name)
+			updateTableInput.TableInput.Description = &descWithPrefix
+			descriptionShouldBeUpdated = true
+		}
+	default:
+		if desc, ok := g.AssetStatePolicy.GenDescription(action, g.PrefixForUpdate, g.OverwriteMode, utils.FormatPlainText, aws.ToString(glueTable.Table.Description), tableAsset.Description); ok {
+			desc = g.fitDescription(utils.FieldGlueTableDescription, tableFQN, tableAsset.ID, desc)
+			g.Logger.Debug("Table will be updated: %s action %s", *glueTable.Table.Name, action)
+			updateTableInput.TableInput.Description = &desc
+			descriptionShouldBeUpdated = true
+		}
+	}
+	if descriptionShouldBeUpdated {
+		tableShouldBeUpdated = true
+	}
+	// MEMO: The comment follows the description managed by the agent, including the one written before the option is enabled.
+	isDescriptionManaged := descriptionShouldBeUpdated || strings.HasPrefix(aws.ToString(glueTable.Table.Description), g.PrefixForUpdate)
+	if g.CommentParameterSyncEnabled && isDescriptionManaged && (!isCommentForeign || g.OverwriteMode == utils.OverwriteAll) {
+		if parameters, ok := genCommentUpdatedParameters(updateTableInput.TableInput.Parameters, aws.ToString(updateTableInput.TableInput.Description)); ok {
+			g.Logger.Debug("Table comment parameter will be updated: %s", *glueTable.Table.Name)
+			updateTableInput.TableInput.Parameters = parameters
+			tableShouldBeUpdated = true
+		}
+	}
+	if parameters, ok := genDeprecationUpdatedParameters(updateTableInput.TableInput.Parameters, g.DeprecationParameterKey, action == utils.AssetStateDeprecate); ok {
+		updateTableInput.TableInput.Parameters = parameters
+		tableShouldBeUpdated = true
+	}
+	if parameters, ok := genMetadataUpdatedParameters(updateTableInput.TableInput.Parameters, g.MetadataParameterKeys, tableAsset, action == utils.AssetStateClear, syncedAt); ok {
+		g.Logger.Debug("Table parameters will be updated: %s", *glueTable.Table.Name)
+		updateTableInput.TableInput.Parameters = parameters
+		tableShouldBeUpdated = true
+	}
+	updatedColumns, columnShouldBeUpdated := getDescUpdatedColumns(g.PrefixForUpdate, g.OverwriteMode, g.AssetStatePolicy, glueTable, storageColumnAssets)
+	if columnShouldBeUpdated {
+		updateTableInput.TableInput.StorageDescriptor.Columns = g.fitColumnComments(tableFQN, updatedColumns, storageColumnAssets)
+	}
+	if updateTableInput.TableInput.StorageDescriptor != nil {
+		if columns, ok := genMetadataUpdatedColumns(g.MetadataParameterKeys, g.AssetStatePolicy, updateTableInput.TableInput.StorageDescriptor.Columns, storageColumnAssets, syncedAt); ok {
+			updateTableInput.TableInput.StorageDescriptor.Columns = columns
+			columnShouldBeUpdated = true
+		}
+	}
+	if isPrestoView(glueTable.Table) && updateTableInput.TableInput.StorageDescriptor != nil {
+		comments := genViewColumnComments(g.PrefixForUpdate, updateTableInput.TableInput.StorageDescriptor.Columns)
+		viewOriginalText, ok, err := genCommentUpdatedPrestoView(aws.ToString(glueTable.Table.ViewOriginalText), comments)
+		switch {
+		case err != nil:
+			g.Report.Add(report.WARNING, "athena", tableFQN, "view_original_text", fmt.Sprintf("Failed to update column comments in the view definition. %s", err.Error()))
+		case ok:
+			g.Logger.Debug("View definition will be updated: %s", *glueTable.Table.Name)
+			updateTableInput.TableInput.ViewOriginalText = &viewOriginalText
+			columnShouldBeUpdated = true
+		}
+	}
+	updatedPartitionKeys, partitionKeyShouldBeUpdated := getDescUpdatedPartitionKeys(g.PrefixForUpdate, g.OverwriteMode, g.AssetStatePolicy, glueTable, columnAssets)
+	if partitionKeyShouldBeUpdated {
+		updateTableInput.TableInput.PartitionKeys = g.fitColumnComments(tableFQN, updatedPartitionKeys, columnAssets)
+		columnShouldBeUpdated = true
+	}
+	if partitionKeys, ok := genMetadataUpdatedColumns(g.MetadataParameterKeys, g.AssetStatePolicy, updateTableInput.TableInput.PartitionKeys, columnAssets, syncedAt); ok {
+		updateTableInput.TableInput.PartitionKeys = partitionKeys
+		columnShouldBeUpdated = true
+	}
+	return updateTableInput, tableShouldBeUpdated, columnShouldBeUpdated
+}
@@ -0,0 +1,55 @@
+package glue
+
+import (
+	"quollio-reverse-agent/common/logger"
+	"quollio-reverse-agent/common/utils"
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGenTableUpdateKeepsChangeOfAnotherWriter(t *testing.T) {
+	g := &GlueConnector{
+		AssetStatePolicy: utils.DefaultAssetStatePolicy(),
+		OverwriteMode:    utils.OverwriteIfEmpty,
+		PrefixForUpdate:  utils.DefaultPrefix,
+		Logger:           logger.NewBuiltinLogger(),
+	}
+	// MEMO: The crawler added the column and changed the type of id after the first read.
+	freshTable := &glueService.GetTableOutput{Table: &types.Table{
+		Name:         aws.String("orders"),
+		DatabaseName: aws.String("sales"),
+		VersionId:    aws.String("2"),
+		Parameters:   map[string]string{"classification": "parquet"},
+		StorageDescriptor: &types.StorageDescriptor{Columns: []types.Column{
+			{Name: aws.String("id"), Type: aws.String("bigint")},
+			{Name: aws.String("amount"), Type: aws.String("double")},
+		}},
+	}}
+	tableAsset := qdc.Data{PhysicalName: "orders", Description: "Orders"}
+	columnAssets := []qdc.Data{{PhysicalName: "id", Description: "Order ID"}}
+
+	input, tableUpdated, columnUpdated := g.genTableUpdate(freshTable, tableAsset, columnAssets, columnAssets, utils.AssetStateUpdate, "sales.orders", time.Now())
+	if !tableUpdated || !columnUpdated {
+		t.Fatalf("want the table and the columns to be updated but got %v %v", tableUpdated, columnUpdated)
+	}
+	if diff := cmp.Diff(utils.DefaultPrefix+"Orders", aws.ToString(input.TableInput.Description)); diff != "" {
+		t.Errorf("description mismatch (-want +got):\n%s", diff)
+	}
+	wantColumns := []types.Column{
+		{Name: aws.String("id"), Type: aws.String("bigint"), Comment: aws.String(utils.DefaultPrefix + "Order ID")},
+		{Name: aws.String("amount"), Type: aws.String("double")},
+	}
+	if diff := cmp.Diff(wantColumns, input.TableInput.StorageDescriptor.Columns, cmpopts.IgnoreUnexported(types.Column{})); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("parquet", input.TableInput.Parameters["classification"]); diff != "" {
+		t.Errorf("parameters mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,173 @@
+package glue
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/repository/glue"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+// genTableChanges returns the changes of the description and the comments for the log.
+func genTableChanges(previous, updated *types.Table) []string {
+	var changes []string
+	if aws.ToString(previous.Description) != aws.ToString(updated.Description) {
+		changes = append(changes, fmt.Sprintf("Description: %q -> %q", aws.ToString(previous.Description), aws.ToString(updated.Description)))
+	}
+	genColumnChanges := func(kind string, previousColumns, updatedColumns []types.Column) {
+		previousComments := make(map[string]string)
+		for _, column := range previousColumns {
+			previousComments[aws.ToString(column.Name)] = aws.ToString(column.Comment)
+		}
+		for _, column := range updatedColumns {
+			previousComment, updatedComment := previousComments[aws.ToString(column.Name)], aws.ToString(column.Comment)
+			if previousComment != updatedComment {
+				changes = append(changes, fmt.Sprintf("%s %s: %q -> %q", kind, aws.ToString(column.Name), previousComment, updatedComment))
+			}
+		}
+	}
+	if previous.StorageDescriptor != nil && updated.StorageDescriptor != nil {
+		genColumnChanges("Column", previous.StorageDescriptor.Columns, updated.StorageDescriptor.Columns)
+	}
+	genColumnChanges("PartitionKey", previous.PartitionKeys, updated.PartitionKeys)
+	return changes
+}
+
+// genUnexpectedTableChanges returns the changes of the table definition other than the metadata that the agent writes.
+// Column order, types, partition keys, serde and location must be the same as before the update.
+func genUnexpectedTableChanges(previous, updated *types.Table) []string {
+	var changes []string
+	changes = append(changes, genUnexpectedColumnChanges("Columns", storageColumns(previous), storageColumns(updated))...)
+	changes = append(changes, genUnexpectedColumnChanges("PartitionKeys", previous.PartitionKeys, updated.PartitionKeys)...)
+	if (previous.StorageDescriptor == nil) != (updated.StorageDescriptor == nil) {
+		return append(changes, "StorageDescriptor is added or removed.")
+	}
+	if previous.StorageDescriptor != nil {
+		if aws.ToString(previous.StorageDescriptor.Location) != aws.ToString(updated.StorageDescriptor.Location) {
+			changes = append(changes, fmt.Sprintf("Location: %q -> %q", aws.ToString(previous.StorageDescriptor.Location), aws.ToString(updated.StorageDescriptor.Location)))
+		}
+		if !reflect.DeepEqual(previous.StorageDescriptor.SerdeInfo, updated.StorageDescriptor.SerdeInfo) {
+			changes = append(changes, "SerdeInfo is changed.")
+		}
+		// MEMO: The rest of the storage descriptor, such as the formats and the buckets, must not be changed either.
+		previousStorageDescriptor, updatedStorageDescriptor := *previous.StorageDescriptor, *updated.StorageDescriptor
+		previousStorageDescriptor.Columns, updatedStorageDescriptor.Columns = nil, nil
+		previousStorageDescriptor.Location, updatedStorageDescriptor.Location = nil, nil
+		previousStorageDescriptor.SerdeInfo, updatedStorageDescriptor.SerdeInfo = nil, nil
+		if !reflect.DeepEqual(previousStorageDescriptor, updatedStorageDescriptor) {
+			changes = append(changes, "StorageDescriptor is changed.")
+		}
+	}
+	if aws.ToString(previous.TableType) != aws.ToString(updated.TableType) {
+		changes = append(changes, fmt.Sprintf("TableType: %q -> %q", aws.ToString(previous.TableType), aws.ToString(updated.TableType)))
+	}
+	if aws.ToString(previous.ViewExpandedText) != aws.ToString(updated.ViewExpandedText) {
+		changes = append(changes, "ViewExpandedText is changed.")
+	}
+	return changes
+}
+
+func storageColumns(glueTable *types.Table) []types.Column {
+	if glueTable.StorageDescriptor == nil {
+		return nil
+	}
+	return glueTable.StorageDescriptor.Columns
+}
+
+func genUnexpectedColumnChanges(kind string, previous, updated []types.Column) []string {
+	if len(previous) != len(updated) {
+		return []string{fmt.Sprintf("%s: the number of columns %d -> %d", kind, len(previous), len(updated))}
+	}
+	var changes []string
+	for i := range previous {
+		previousName, updatedName := aws.ToString(previous[i].Name), aws.ToString(updated[i].Name)
+		previousType, updatedType := aws.ToString(previous[i].Type), aws.ToString(updated[i].Type)
+		if previousName != updatedName || previousType != updatedType {
+			changes = append(changes, fmt.Sprintf("%s[%d]: %s %s -> %s %s", kind, i, previousName, previousType, updatedName, updatedType))
+		}
+	}
+	return changes
+}
+
+// genNextVersionID returns the VersionId of the version that UpdateTable creates from the version.
+// Empty is returned if the VersionId isn't a number.
+func genNextVersionID(versionID string) string {
+	value, err := strconv.Atoi(versionID)
+	if err != nil {
+		return ""
+	}
+	return strconv.Itoa(value + 1)
+}
+
+// verifyTableUpdate reads the table again after the update and checks that only the metadata that the agent writes is changed.
+// On any other change, the table is rolled back to the previous definition and the change is reported as an alert.
+// The table is rolled back only when its current version is the one created by the agent. If another writer updated it after
+// the agent, the version of the agent is verified and the change is only reported, so that the change of the writer is kept.
+func (g *GlueConnector) verifyTableUpdate(glueRepo *glue.GlueClient, previous *glueService.GetTableOutput, tableFQN string) error {
+	catalogID, dbName, tableName := aws.ToString(previous.Table.CatalogId), aws.ToString(previous.Table.DatabaseName), aws.ToString(previous.Table.Name)
+	updated, err := glueRepo.GetTable(catalogID, dbName, tableName)
+	if err != nil {
+		if code.Decide(err) == code.Abort {
+			return err
+		}
+		g.Report.Add(report.WARNING, "athena", tableFQN, "schema", fmt.Sprintf("Failed to read the table to verify the update. %s", err.Error()))
+		return nil
+	}
+	agentVersionID := genNextVersionID(aws.ToString(previous.Table.VersionId))
+	isAgentVersionCurrent := agentVersionID != "" && aws.ToString(updated.Table.VersionId) == agentVersionID
+	agentTable := updated.Table
+	if !isAgentVersionCurrent {
+		if agentVersionID == "" {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "schema", fmt.Sprintf("Skipped to verify the update because the version created by the agent is unknown. previous version %s", aws.ToString(previous.Table.VersionId)))
+			return nil
+		}
+		version, err := glueRepo.GetTableVersion(catalogID, dbName, tableName, agentVersionID)
+		if err != nil {
+			if code.Decide(err) == code.Abort {
+				return err
+			}
+			g.Report.Add(report.WARNING, "athena", tableFQN, "schema", fmt.Sprintf("Failed to read the version %s to verify the update. %s", agentVersionID, err.Error()))
+			return nil
+		}
+		if version.TableVersion == nil || version.TableVersion.Table == nil {
+			g.Report.Add(report.WARNING, "athena", tableFQN, "schema", fmt.Sprintf("Skipped to verify the update because the version %s has no table.", agentVersionID))
+			return nil
+		}
+		agentTable = version.TableVersion.Table
+	}
+	for _, change := range genTableChanges(previous.Table, agentTable) {
+		g.Logger.Debug("Updated table %s. %s", tableFQN, change)
+	}
+	changes := genUnexpectedTableChanges(previous.Table, agentTable)
+	if len(changes) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("The table definition changed unexpectedly with the update. %s", strings.Join(changes, " "))
+	tableFormat := detectTableFormat(agentTable)
+	// MEMO: The schema of the open table formats is owned by the writer, so it can't be rolled back safely.
+	if isOpenTableFormat(tableFormat) {
+		g.Report.Add(report.ALERT, "athena", tableFQN, "schema", fmt.Sprintf("%s It was not rolled back because the %s table schema is owned by the writer.", msg, tableFormat))
+		return nil
+	}
+	if !isAgentVersionCurrent {
+		g.Report.Add(report.ALERT, "athena", tableFQN, "schema", fmt.Sprintf("%s It was not rolled back because the table was updated after the version %s of the agent. current version %s", msg, agentVersionID, aws.ToString(updated.Table.VersionId)))
+		return nil
+	}
+	rollbackTableInput := genUpdateTableInput(previous)
+	rollbackTableInput.VersionId = updated.Table.VersionId
+	if _, err := glueRepo.UpdateTable(catalogID, dbName, rollbackTableInput); err != nil {
+		if code.Decide(err) == code.Abort {
+			return err
+		}
+		g.Report.Add(report.ALERT, "athena", tableFQN, "schema", fmt.Sprintf("%s Failed to roll back. %s", msg, err.Error()))
+		return nil
+	}
+	g.Report.Add(report.ALERT, "athena", tableFQN, "schema", fmt.Sprintf("%s It was rolled back to the previous definition.", msg))
+	return nil
+}
@@ -0,0 +1,90 @@
+package glue
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func genVerifiedTable(comment, location string, columnTypes ...string) *types.Table {
+	var columns []types.Column
+	for i, columnType := range columnTypes {
+		columns = append(columns, types.Column{Name: aws.String([]string{"id", "amount", "note"}[i]), Type: aws.String(columnType), Comment: aws.String(comment)})
+	}
+	return &types.Table{
+		Name:          aws.String("orders"),
+		Description:   aws.String(comment),
+		PartitionKeys: []types.Column{{Name: aws.String("dt"), Type: aws.String("string"), Comment: aws.String(comment)}},
+		StorageDescriptor: &types.StorageDescriptor{
+			Columns:   columns,
+			Location:  aws.String(location),
+			SerdeInfo: &types.SerDeInfo{SerializationLibrary: aws.String("org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe")},
+		},
+	}
+}
+
+func TestGenUnexpectedTableChanges(t *testing.T) {
+	previous := genVerifiedTable("", "s3://bucket/orders", "int", "double")
+	testCases := []struct {
+		name    string
+		updated *types.Table
+		want    []string
+	}{
+		{
+			name:    "only comments",
+			updated: genVerifiedTable("comment", "s3://bucket/orders", "int", "double"),
+			want:    nil,
+		},
+		{
+			name:    "type",
+			updated: genVerifiedTable("comment", "s3://bucket/orders", "bigint", "double"),
+			want:    []string{"Columns[0]: id int -> id bigint"},
+		},
+		{
+			name:    "column added and location",
+			updated: genVerifiedTable("", "s3://bucket/orders_v2", "int", "double", "string"),
+			want:    []string{"Columns: the number of columns 2 -> 3", `Location: "s3://bucket/orders" -> "s3://bucket/orders_v2"`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, genUnexpectedTableChanges(previous, tc.updated)); diff != "" {
+				t.Errorf("changes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	serdeChanged := genVerifiedTable("", "s3://bucket/orders", "int", "double")
+	serdeChanged.StorageDescriptor.SerdeInfo = &types.SerDeInfo{SerializationLibrary: aws.String("org.openx.data.jsonserde.JsonSerDe")}
+	if diff := cmp.Diff([]string{"SerdeInfo is changed."}, genUnexpectedTableChanges(previous, serdeChanged)); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenTableChanges(t *testing.T) {
+	previous := genVerifiedTable("", "s3://bucket/orders", "int")
+	updated := genVerifiedTable("new", "s3://bucket/orders", "int")
+	want := []string{`Description: "" -> "new"`, `Column id: "" -> "new"`, `PartitionKey dt: "" -> "new"`}
+	if diff := cmp.Diff(want, genTableChanges(previous, updated)); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenNextVersionID(t *testing.T) {
+	testCases := []struct {
+		versionID string
+		want      string
+	}{
+		{versionID: "1", want: "2"},
+		{versionID: "41", want: "42"},
+		{versionID: "", want: ""},
+		{versionID: "latest", want: ""},
+	}
+	for _, tc := range testCases {
+		if got := genNextVersionID(tc.versionID); got != tc.want {
+			t.Errorf("version %q: want %q but got %q", tc.versionID, tc.want, got)
+		}
+	}
+}
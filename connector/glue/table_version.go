@@ -0,0 +1,187 @@
+package glue
+
+import (
+	"fmt"
+	"quollio-reverse-agent/common/code"
+	"quollio-reverse-agent/common/report"
+	"quollio-reverse-agent/repository/glue"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+)
+
+// maxDeleteTableVersions is the number of the versions that BatchDeleteTableVersion accepts at once.
+const maxDeleteTableVersions = 100
+
+// parseTableVersionRetention returns the number of the versions created by the agent to keep. 0 means no pruning.
+func parseTableVersionRetention(retention string) (int, error) {
+	if retention == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(retention)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("invalid table version retention %s. It must be a positive integer", retention)
+	}
+	return value, nil
+}
+
+// diffTableInput returns whether the input is changed from the original, and whether only the metadata is changed.
+// The metadata are the description, the comments, the parameters of the table and the columns, and the view definition
+// whose column comments the agent writes.
+func diffTableInput(original, updated types.TableInput) (bool, bool) {
+	if reflect.DeepEqual(original, updated) {
+		return false, false
+	}
+	updated.Description = original.Description
+	updated.Parameters = original.Parameters
+	updated.ViewOriginalText = original.ViewOriginalText
+	updated.PartitionKeys = restoreColumnMetadata(original.PartitionKeys, updated.PartitionKeys)
+	if original.StorageDescriptor != nil && updated.StorageDescriptor != nil {
+		storageDescriptor := *updated.StorageDescriptor
+		storageDescriptor.Columns = restoreColumnMetadata(original.StorageDescriptor.Columns, storageDescriptor.Columns)
+		updated.StorageDescriptor = &storageDescriptor
+	}
+	return true, reflect.DeepEqual(original, updated)
+}
+
+// restoreColumnMetadata returns the columns whose comments and parameters are restored from the original columns.
+// The columns are returned as they are if they are added, removed or reordered.
+func restoreColumnMetadata(original, updated []types.Column) []types.Column {
+	if len(original) != len(updated) {
+		return updated
+	}
+	restored := slices.Clone(updated)
+	for i := range restored {
+		if aws.ToString(restored[i].Name) != aws.ToString(original[i].Name) {
+			return updated
+		}
+		restored[i].Comment = original[i].Comment
+		restored[i].Parameters = original[i].Parameters
+	}
+	return restored
+}
+
+// isAgentTableVersion returns true when the version changes only the metadata from the previous version, and all of the
+// changed descriptions and comments have the prefix. Such versions are regarded as created by the agent.
+// A cleared description or comment is regarded as written by the agent only when the version also changes the sync time
+// parameter of the agent, because a user can clear it too.
+func isAgentTableVersion(prefix, syncedAtKey string, previous, current *types.Table) bool {
+	if previous == nil || current == nil {
+		return false
+	}
+	previousInput := genUpdateTableInput(&glueService.GetTableOutput{Table: previous}).TableInput
+	currentInput := genUpdateTableInput(&glueService.GetTableOutput{Table: current}).TableInput
+	changed, metadataOnly := diffTableInput(*previousInput, *currentInput)
+	if !changed || !metadataOnly {
+		return false
+	}
+	hasSyncMarker := syncedAtKey != "" && previous.Parameters[syncedAtKey] != current.Parameters[syncedAtKey]
+	isManaged := func(before, after *string) bool {
+		value := aws.ToString(after)
+		switch {
+		case aws.ToString(before) == value:
+			return true
+		case value == "":
+			return hasSyncMarker
+		default:
+			return prefix != "" && strings.HasPrefix(value, prefix)
+		}
+	}
+	if !isManaged(previous.Description, current.Description) {
+		return false
+	}
+	var previousColumns, currentColumns []types.Column
+	previousColumns = append(previousColumns, previous.PartitionKeys...)
+	currentColumns = append(currentColumns, current.PartitionKeys...)
+	if previous.StorageDescriptor != nil && current.StorageDescriptor != nil {
+		previousColumns = append(previousColumns, previous.StorageDescriptor.Columns...)
+		currentColumns = append(currentColumns, current.StorageDescriptor.Columns...)
+	}
+	if len(previousColumns) != len(currentColumns) {
+		return false
+	}
+	for i := range currentColumns {
+		if !isManaged(previousColumns[i].Comment, currentColumns[i].Comment) {
+			return false
+		}
+	}
+	return true
+}
+
+// genPrunedTableVersionIDs returns the IDs of the versions created by the agent except for the latest retention versions.
+// The current version is never returned.
+func genPrunedTableVersionIDs(prefix, syncedAtKey string, versions []types.TableVersion, retention int) []string {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b types.TableVersion) int {
+		return compareVersionID(aws.ToString(a.VersionId), aws.ToString(b.VersionId))
+	})
+	var agentVersionIDs []string
+	// MEMO: The last version is the current one.
+	for i := 1; i < len(sorted)-1; i++ {
+		if isAgentTableVersion(prefix, syncedAtKey, sorted[i-1].Table, sorted[i].Table) {
+			agentVersionIDs = append(agentVersionIDs, aws.ToString(sorted[i].VersionId))
+		}
+	}
+	if len(agentVersionIDs) <= retention {
+		return nil
+	}
+	return agentVersionIDs[:len(agentVersionIDs)-retention]
+}
+
+func compareVersionID(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+	return aNum - bNum
+}
+
+// pruneTableVersions deletes the old versions created by the agent beyond the retention count.
+// The failures are reported, because the pruning is the maintenance that doesn't affect the sync.
+func (g *GlueConnector) pruneTableVersions(glueRepo *glue.GlueClient, glueTable *types.Table, tableFQN string) error {
+	catalogID, dbName, tableName := aws.ToString(glueTable.CatalogId), aws.ToString(glueTable.DatabaseName), aws.ToString(glueTable.Name)
+	var versions []types.TableVersion
+	var nextToken string
+	for {
+		output, err := glueRepo.GetTableVersions(catalogID, dbName, tableName, nextToken)
+		if err != nil {
+			if code.Decide(err) == code.Abort {
+				return err
+			}
+			g.Report.Add(report.WARNING, "athena", tableFQN, "version", fmt.Sprintf("Failed to read the table versions to prune. %s", err.Error()))
+			return nil
+		}
+		versions = append(versions, output.TableVersions...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		nextToken = *output.NextToken
+	}
+	versionIDs := genPrunedTableVersionIDs(g.PrefixForUpdate, g.MetadataParameterKeys.SyncedAt, versions, g.TableVersionRetention)
+	for start := 0; start < len(versionIDs); start += maxDeleteTableVersions {
+		chunk := versionIDs[start:min(start+maxDeleteTableVersions, len(versionIDs))]
+		output, err := glueRepo.BatchDeleteTableVersion(catalogID, dbName, tableName, chunk)
+		if err != nil {
+			if code.Decide(err) == code.Abort {
+				return err
+			}
+			g.Report.Add(report.WARNING, "athena", tableFQN, "version", fmt.Sprintf("Failed to prune the table versions. %s", err.Error()))
+			return nil
+		}
+		for _, versionError := range output.Errors {
+			var errorMessage string
+			if versionError.ErrorDetail != nil {
+				errorMessage = aws.ToString(versionError.ErrorDetail.ErrorMessage)
+			}
+			g.Report.Add(report.WARNING, "athena", tableFQN, "version", fmt.Sprintf("Failed to prune the table version %s. %s", aws.ToString(versionError.VersionId), errorMessage))
+		}
+		g.Logger.Debug("Pruned %d table versions. table name %s", len(chunk)-len(output.Errors), tableFQN)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package glue
+
+import (
+	"quollio-reverse-agent/common/utils"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueService "github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func genVersionTable(desc, idComment, idType string) *types.Table {
+	return &types.Table{
+		Name:        aws.String("orders"),
+		Description: aws.String(desc),
+		StorageDescriptor: &types.StorageDescriptor{Columns: []types.Column{
+			{Name: aws.String("id"), Type: aws.String(idType), Comment: aws.String(idComment)},
+		}},
+	}
+}
+
+func TestDiffTableInput(t *testing.T) {
+	original := genVersionTable("", "", "int")
+	testCases := []struct {
+		name             string
+		updated          *types.Table
+		wantChanged      bool
+		wantMetadataOnly bool
+	}{
+		{name: "unchanged", updated: genVersionTable("", "", "int"), wantChanged: false, wantMetadataOnly: false},
+		{name: "comments", updated: genVersionTable(utils.DefaultPrefix+"Orders", utils.DefaultPrefix+"ID", "int"), wantChanged: true, wantMetadataOnly: true},
+		{name: "schema", updated: genVersionTable("", "", "bigint"), wantChanged: true, wantMetadataOnly: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalInput := genUpdateTableInput(&glueService.GetTableOutput{Table: original}).TableInput
+			updatedInput := genUpdateTableInput(&glueService.GetTableOutput{Table: tc.updated}).TableInput
+			changed, metadataOnly := diffTableInput(*originalInput, *updatedInput)
+			if changed != tc.wantChanged || metadataOnly != tc.wantMetadataOnly {
+				t.Errorf("want %v %v but got %v %v", tc.wantChanged, tc.wantMetadataOnly, changed, metadataOnly)
+			}
+		})
+	}
+}
+
+func TestGenPrunedTableVersionIDs(t *testing.T) {
+	prefix := utils.DefaultPrefix
+	syncedAtKey := defaultSyncedAtParameterKey
+	withSyncedAt := func(table *types.Table, syncedAt string) *types.Table {
+		table.Parameters = map[string]string{syncedAtKey: syncedAt}
+		return table
+	}
+	versions := []types.TableVersion{
+		{VersionId: aws.String("10"), Table: genVersionTable(prefix+"Orders v3", "", "bigint")},
+		{VersionId: aws.String("1"), Table: genVersionTable("", "", "int")},
+		{VersionId: aws.String("2"), Table: genVersionTable(prefix+"Orders", "", "int")},
+		{VersionId: aws.String("3"), Table: genVersionTable(prefix+"Orders v2", "", "int")},
+		{VersionId: aws.String("4"), Table: genVersionTable(prefix+"Orders v2", "written by user", "int")},
+		{VersionId: aws.String("5"), Table: genVersionTable(prefix+"Orders v2", "written by user", "bigint")},
+		{VersionId: aws.String("6"), Table: genVersionTable(prefix+"Orders v3", "written by user", "bigint")},
+		{VersionId: aws.String("7"), Table: genVersionTable(prefix+"Orders v3", "", "bigint")},
+		{VersionId: aws.String("8"), Table: genVersionTable(prefix+"Orders v3", "written by user", "bigint")},
+		{VersionId: aws.String("9"), Table: withSyncedAt(genVersionTable(prefix+"Orders v3", "", "bigint"), "2024-04-10T09:00:00Z")},
+	}
+	// MEMO: 4 changes a comment without the prefix and 5 changes the schema. 7 is cleared by a user, while 9 is cleared by the agent
+	// with the sync time. 8 restores the comment of the user without the prefix. 10 is the current version.
+	if diff := cmp.Diff([]string{"2", "3"}, genPrunedTableVersionIDs(prefix, syncedAtKey, versions, 2)); diff != "" {
+		t.Errorf("pruned versions mismatch (-want +got):\n%s", diff)
+	}
+	if got := genPrunedTableVersionIDs(prefix, syncedAtKey, versions, 4); got != nil {
+		t.Errorf("want no versions to be pruned but got %v", got)
+	}
+}
+
+func TestIsAgentTableVersionRequiresMarkerToClear(t *testing.T) {
+	prefix := utils.DefaultPrefix
+	previous := genVersionTable("written by user", "", "int")
+	cleared := genVersionTable("", "", "int")
+	if isAgentTableVersion(prefix, defaultSyncedAtParameterKey, previous, cleared) {
+		t.Errorf("the description cleared without the sync time must not be regarded as written by the agent")
+	}
+	cleared.Parameters = map[string]string{defaultSyncedAtParameterKey: "2024-04-10T09:00:00Z"}
+	if !isAgentTableVersion(prefix, defaultSyncedAtParameterKey, previous, cleared) {
+		t.Errorf("the description cleared with the sync time must be regarded as written by the agent")
+	}
+}
@@ -43,14 +43,20 @@ func runReverseAgent(systemName *string) error {
 	default:
 		prefixForUpdate = os.Getenv("PREFIX_FOR_UPDATE")
 	}
+	assetStatePolicy, err := utils.NewAssetStatePolicy(os.Getenv("QDC_LOST_ASSET_POLICY"), os.Getenv("QDC_ARCHIVED_ASSET_POLICY"), os.Getenv("DEPRECATION_NOTICE"))
+	if err != nil {
+		logger.Error("Failed to NewAssetStatePolicy: %s", err.Error())
+		return fmt.Errorf("Failed to NewAssetStatePolicy")
+	}
 	logger.Debug("Overwrite mode: %s", overwriteMode)
 	logger.Debug("PrefixForUpdate: %s", prefixForUpdate)
+	logger.Debug("AssetStatePolicy: %+v", assetStatePolicy)
 
 	logger.Info("Start ReflectMetadataToDataCatalog")
 	switch *systemName {
 	case "bigquery":
 		logger.Info("Start to create NewBigQueryConnector.")
-		BqConnector, err := bigquery.NewBigqueryConnector(prefixForUpdate, overwriteMode, logger)
+		BqConnector, err := bigquery.NewBigqueryConnector(prefixForUpdate, overwriteMode, assetStatePolicy, logger)
 		if err != nil {
 			logger.Error("Failed to NewBigqueryConnector")
 			return fmt.Errorf("Failed to NewBigqueryConnector")
@@ -63,7 +69,7 @@ func runReverseAgent(systemName *string) error {
 		}
 	case "athena":
 		logger.Info("Start to create NewGlueConnector.")
-		GlueConnector, err := glue.NewGlueConnector(prefixForUpdate, overwriteMode, logger)
+		GlueConnector, err := glue.NewGlueConnector(prefixForUpdate, overwriteMode, assetStatePolicy, logger)
 		if err != nil {
 			logger.Error("Failed to NewGlueConnector")
 			return fmt.Errorf("Failed to NewGlueConnector")
@@ -76,7 +82,7 @@ func runReverseAgent(systemName *string) error {
 		}
 	case "denodo":
 		logger.Info("Start to create DenodoConnector.")
-		DenodoConnector, err := denodo.NewDenodoConnector(prefixForUpdate, overwriteMode, logger)
+		DenodoConnector, err := denodo.NewDenodoConnector(prefixForUpdate, overwriteMode, assetStatePolicy, logger)
 		if err != nil {
 			logger.Error("Failed to NewDenodoConnector")
 			return fmt.Errorf("Failed to NewDenodoConnector")
@@ -3,6 +3,7 @@ package bigquery
 import (
 	"context"
 	"encoding/json"
+	"quollio-reverse-agent/common/code"
 
 	"cloud.google.com/go/bigquery"
 	"golang.org/x/oauth2/google"
@@ -36,44 +37,50 @@ func NewBigQueryClient(serviceAccountCredentialJson string) (BigQueryClient, err
 	return client, nil
 }
 
-func (b *BigQueryClient) GetDatasetMetadata(datasetID string) (*bigquery.DatasetMetadata, error) {
+// dataset returns the dataset in the project. The project of the client is used if projectID is empty.
+func (b *BigQueryClient) dataset(projectID, datasetID string) *bigquery.Dataset {
+	if projectID == "" {
+		return b.BQClient.Dataset(datasetID)
+	}
+	return b.BQClient.DatasetInProject(projectID, datasetID)
+}
+
+func (b *BigQueryClient) GetDatasetMetadata(projectID, datasetID string) (*bigquery.DatasetMetadata, error) {
 	ctx := context.Background()
-	dataset := b.BQClient.Dataset(datasetID)
+	dataset := b.dataset(projectID, datasetID)
 	datasetMetadata, err := dataset.Metadata(ctx)
 	if err != nil {
-		return nil, err
+		return nil, code.FromGoogle("bigquery", "GetDatasetMetadata", err)
 	}
 	return datasetMetadata, nil
 }
 
-func (b *BigQueryClient) UpdateDatasetDescription(datasetID, description string) (*bigquery.DatasetMetadata, error) {
+func (b *BigQueryClient) UpdateDatasetMetadata(projectID, datasetID string, metadata bigquery.DatasetMetadataToUpdate) (*bigquery.DatasetMetadata, error) {
 	ctx := context.Background()
-	dataset := b.BQClient.Dataset(datasetID)
-	datasetMetadata, err := dataset.Update(ctx, bigquery.DatasetMetadataToUpdate{
-		Description: description,
-	}, "")
+	dataset := b.dataset(projectID, datasetID)
+	datasetMetadata, err := dataset.Update(ctx, metadata, "")
 	if err != nil {
-		return nil, err
+		return nil, code.FromGoogle("bigquery", "UpdateDatasetMetadata", err)
 	}
 	return datasetMetadata, nil
 }
 
-func (b *BigQueryClient) GetTableMetadata(datasetID, tableName string) (*bigquery.TableMetadata, error) {
+func (b *BigQueryClient) GetTableMetadata(projectID, datasetID, tableName string) (*bigquery.TableMetadata, error) {
 	ctx := context.Background()
-	table := b.BQClient.Dataset(datasetID).Table(tableName)
+	table := b.dataset(projectID, datasetID).Table(tableName)
 	tableMetadata, err := table.Metadata(ctx)
 	if err != nil {
-		return nil, err
+		return nil, code.FromGoogle("bigquery", "GetTableMetadata", err)
 	}
 	return tableMetadata, nil
 }
 
-func (b *BigQueryClient) UpdateTableMetadata(datasetID, tableName string, metadata bigquery.TableMetadataToUpdate) (*bigquery.TableMetadata, error) {
+func (b *BigQueryClient) UpdateTableMetadata(projectID, datasetID, tableName string, metadata bigquery.TableMetadataToUpdate) (*bigquery.TableMetadata, error) {
 	ctx := context.Background()
-	table := b.BQClient.Dataset(datasetID).Table(tableName)
+	table := b.dataset(projectID, datasetID).Table(tableName)
 	tableMetadata, err := table.Update(ctx, metadata, "")
 	if err != nil {
-		return nil, err
+		return nil, code.FromGoogle("bigquery", "UpdateTableMetadata", err)
 	}
 	return tableMetadata, nil
 }
@@ -2,15 +2,19 @@ package dataplex
 
 import (
 	"context"
+	"errors"
+	"quollio-reverse-agent/common/code"
 
 	datacatalog "cloud.google.com/go/datacatalog/apiv1"
 	"cloud.google.com/go/datacatalog/apiv1/datacatalogpb"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type DataplexClient struct {
-	CatalogClient *datacatalog.Client
+	CatalogClient   *datacatalog.Client
+	PolicyTagClient *datacatalog.PolicyTagManagerClient
 }
 
 func NewDataplexClient(serviceAccountCredentialJson string) (DataplexClient, error) {
@@ -25,8 +29,13 @@ func NewDataplexClient(serviceAccountCredentialJson string) (DataplexClient, err
 	if err != nil {
 		return DataplexClient{}, err
 	}
+	policyTagClient, err := datacatalog.NewPolicyTagManagerClient(ctx, option.WithCredentials(creds))
+	if err != nil {
+		return DataplexClient{}, err
+	}
 	client := DataplexClient{
-		CatalogClient: c,
+		CatalogClient:   c,
+		PolicyTagClient: policyTagClient,
 	}
 
 	return client, nil
@@ -43,7 +52,7 @@ func (d *DataplexClient) ModifyEntryOverview(entryName, entryOverview string) (*
 
 	resp, err := d.CatalogClient.ModifyEntryOverview(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, code.FromGoogle("dataplex", "ModifyEntryOverview", err)
 	}
 
 	return resp, nil
@@ -61,8 +70,197 @@ func (d *DataplexClient) LookupEntry(assetFQN, projectName, location string) (*d
 	}
 	res, err := d.CatalogClient.LookupEntry(ctx, &lookupEntryRequest)
 	if err != nil {
-		return &datacatalogpb.Entry{}, err
+		return &datacatalogpb.Entry{}, code.FromGoogle("dataplex", "LookupEntry", err)
+	}
+
+	return res, nil
+}
+
+// ListTaxonomies returns the taxonomies under the parent such as `projects/{project}/locations/{location}`.
+func (d *DataplexClient) ListTaxonomies(parent string) ([]*datacatalogpb.Taxonomy, error) {
+	ctx := context.Background()
+	it := d.PolicyTagClient.ListTaxonomies(ctx, &datacatalogpb.ListTaxonomiesRequest{
+		Parent: parent,
+	})
+	var taxonomies []*datacatalogpb.Taxonomy
+	for {
+		taxonomy, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, code.FromGoogle("dataplex", "ListTaxonomies", err)
+		}
+		taxonomies = append(taxonomies, taxonomy)
+	}
+	return taxonomies, nil
+}
+
+// CreateTaxonomy creates a taxonomy which enforces fine-grained access control on the columns.
+func (d *DataplexClient) CreateTaxonomy(parent, displayName string) (*datacatalogpb.Taxonomy, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.CreateTaxonomyRequest{
+		Parent: parent,
+		Taxonomy: &datacatalogpb.Taxonomy{
+			DisplayName:          displayName,
+			ActivatedPolicyTypes: []datacatalogpb.Taxonomy_PolicyType{datacatalogpb.Taxonomy_FINE_GRAINED_ACCESS_CONTROL},
+		},
+	}
+	res, err := d.PolicyTagClient.CreateTaxonomy(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "CreateTaxonomy", err)
+	}
+	return res, nil
+}
+
+func (d *DataplexClient) ListPolicyTags(taxonomyName string) ([]*datacatalogpb.PolicyTag, error) {
+	ctx := context.Background()
+	it := d.PolicyTagClient.ListPolicyTags(ctx, &datacatalogpb.ListPolicyTagsRequest{
+		Parent: taxonomyName,
+	})
+	var policyTags []*datacatalogpb.PolicyTag
+	for {
+		policyTag, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, code.FromGoogle("dataplex", "ListPolicyTags", err)
+		}
+		policyTags = append(policyTags, policyTag)
+	}
+	return policyTags, nil
+}
+
+func (d *DataplexClient) CreatePolicyTag(taxonomyName, displayName string) (*datacatalogpb.PolicyTag, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.CreatePolicyTagRequest{
+		Parent: taxonomyName,
+		PolicyTag: &datacatalogpb.PolicyTag{
+			DisplayName: displayName,
+		},
+	}
+	res, err := d.PolicyTagClient.CreatePolicyTag(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "CreatePolicyTag", err)
+	}
+	return res, nil
+}
+
+// GetTagTemplate returns nil without an error if the tag template doesn't exist.
+func (d *DataplexClient) GetTagTemplate(name string) (*datacatalogpb.TagTemplate, error) {
+	ctx := context.Background()
+	tagTemplate, err := d.CatalogClient.GetTagTemplate(ctx, &datacatalogpb.GetTagTemplateRequest{
+		Name: name,
+	})
+	if err != nil {
+		err = code.FromGoogle("dataplex", "GetTagTemplate", err)
+		if code.Is(err, code.NotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return tagTemplate, nil
+}
+
+// CreateTagTemplate creates a tag template under the parent such as `projects/{project}/locations/{location}`.
+func (d *DataplexClient) CreateTagTemplate(parent, tagTemplateID string, tagTemplate *datacatalogpb.TagTemplate) (*datacatalogpb.TagTemplate, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.CreateTagTemplateRequest{
+		Parent:        parent,
+		TagTemplateId: tagTemplateID,
+		TagTemplate:   tagTemplate,
+	}
+	res, err := d.CatalogClient.CreateTagTemplate(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "CreateTagTemplate", err)
+	}
+	return res, nil
+}
+
+func (d *DataplexClient) CreateTagTemplateField(tagTemplateName, fieldID string, field *datacatalogpb.TagTemplateField) (*datacatalogpb.TagTemplateField, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.CreateTagTemplateFieldRequest{
+		Parent:             tagTemplateName,
+		TagTemplateFieldId: fieldID,
+		TagTemplateField:   field,
+	}
+	res, err := d.CatalogClient.CreateTagTemplateField(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "CreateTagTemplateField", err)
+	}
+	return res, nil
+}
+
+// ListTags returns the tags attached to the entry and its columns.
+func (d *DataplexClient) ListTags(entryName string) ([]*datacatalogpb.Tag, error) {
+	ctx := context.Background()
+	it := d.CatalogClient.ListTags(ctx, &datacatalogpb.ListTagsRequest{
+		Parent: entryName,
+	})
+	var tags []*datacatalogpb.Tag
+	for {
+		tag, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, code.FromGoogle("dataplex", "ListTags", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (d *DataplexClient) CreateTag(entryName string, tag *datacatalogpb.Tag) (*datacatalogpb.Tag, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.CreateTagRequest{
+		Parent: entryName,
+		Tag:    tag,
+	}
+	res, err := d.CatalogClient.CreateTag(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "CreateTag", err)
 	}
+	return res, nil
+}
+
+// UpdateTag overwrites the fields of the tag. tag.Name must be set.
+func (d *DataplexClient) UpdateTag(tag *datacatalogpb.Tag) (*datacatalogpb.Tag, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.UpdateTagRequest{
+		Tag: tag,
+	}
+	res, err := d.CatalogClient.UpdateTag(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "UpdateTag", err)
+	}
+	return res, nil
+}
+
+func (d *DataplexClient) DeleteTag(tagName string) error {
+	ctx := context.Background()
+	err := d.CatalogClient.DeleteTag(ctx, &datacatalogpb.DeleteTagRequest{
+		Name: tagName,
+	})
+	if err != nil {
+		return code.FromGoogle("dataplex", "DeleteTag", err)
+	}
+	return nil
+}
 
+// ModifyEntryContacts replaces the contacts of the entry with the given people.
+func (d *DataplexClient) ModifyEntryContacts(entryName string, people []*datacatalogpb.Contacts_Person) (*datacatalogpb.Contacts, error) {
+	ctx := context.Background()
+	req := &datacatalogpb.ModifyEntryContactsRequest{
+		Name: entryName,
+		Contacts: &datacatalogpb.Contacts{
+			People: people,
+		},
+	}
+	res, err := d.CatalogClient.ModifyEntryContacts(ctx, req)
+	if err != nil {
+		return nil, code.FromGoogle("dataplex", "ModifyEntryContacts", err)
+	}
 	return res, nil
 }
@@ -2,6 +2,7 @@ package odbc
 
 import (
 	"fmt"
+	"quollio-reverse-agent/common/code"
 	"quollio-reverse-agent/repository/denodo/odbc/models"
 	"strings"
 	"time"
@@ -53,11 +54,25 @@ func (c *Client) ExecuteQuery(sqlStmt string) error {
 	_, err = c.Conn.Exec(sqlStmt)
 	time.Sleep(500 * time.Millisecond)
 	if err != nil {
-		return fmt.Errorf("Query Execution failed %s", err.Error())
+		category := code.Fatal
+		if isPrivilegesErr(err.Error()) {
+			category = code.Permission
+		}
+		return &code.Error{
+			Service:   "denodo",
+			Operation: "ExecuteQuery",
+			Category:  category,
+			Message:   fmt.Sprintf("Query Execution failed %s", err.Error()),
+			Err:       err,
+		}
 	}
 	return nil
 }
 
+func isPrivilegesErr(errMessage string) bool {
+	return strings.Contains(errMessage, "The user does not have enough privileges")
+}
+
 func (c *Client) GetDatabasesFromVdp(targetDBs []string) (*[]models.GetDatabasesResult, error) {
 	dbQuery, args, err := buildQueryToGetDatabases(targetDBs)
 	if err != nil {
@@ -124,7 +139,7 @@ func (c *Client) UpdateVdpDatabaseDesc(databaseName, description string) error {
 	alterStatement := fmt.Sprintf(`alter database %s '%s'`, databaseName, escapeSingleQuoteInString(description))
 	err := c.ExecuteQuery(alterStatement)
 	if err != nil {
-		return fmt.Errorf("UpdateVdpDatabaseDesc failed %s", err)
+		return fmt.Errorf("UpdateVdpDatabaseDesc failed %w", err)
 	}
 	return nil
 }
@@ -140,7 +155,7 @@ func (c *Client) UpdateVdpTableDesc(getViewResult models.GetViewsResult, descrip
 	)
 	err := c.ExecuteQuery(alterStatement)
 	if err != nil {
-		return fmt.Errorf("UpdateVdpTableDesc failed %s", err)
+		return fmt.Errorf("UpdateVdpTableDesc failed %w", err)
 	}
 	return nil
 }
@@ -155,7 +170,7 @@ func (c *Client) UpdateVdpTableColumnDesc(getViewColumnResult models.GetViewColu
 	)
 	err := c.ExecuteQuery(alterStatement)
 	if err != nil {
-		return fmt.Errorf("UpdateVdpTableColumnDesc failed error: %s. query: %s", err, alterStatement)
+		return fmt.Errorf("UpdateVdpTableColumnDesc failed error: %w. query: %s", err, alterStatement)
 	}
 	return nil
 }
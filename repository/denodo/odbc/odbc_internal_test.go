@@ -1,6 +1,7 @@
 package odbc
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -147,3 +148,33 @@ func equal(a, b []interface{}) bool {
 	}
 	return true
 }
+
+func TestIsPrivilegesErr(t *testing.T) {
+	testCases := []struct {
+		Input  string
+		Expect bool
+	}{
+		{
+			Input:  "",
+			Expect: false,
+		},
+		{
+			Input:  "xxx",
+			Expect: false,
+		},
+		{
+			Input:  "Failed to ReflectMetadataToDataCatalog, UpdateVdpDatabaseDesc failed Query Execution failed pq: error modifying database: The user does not have enough privileges or does not have ADMIN privileges",
+			Expect: true,
+		},
+		{
+			Input:  "Failed to ReflectMetadataToDataCatalog,The user does not have enough privileges or does not have ADMIN privileges",
+			Expect: true,
+		},
+	}
+	for _, testCase := range testCases {
+		res := isPrivilegesErr(testCase.Input)
+		if !reflect.DeepEqual(res, testCase.Expect) {
+			t.Errorf("want %+v but got %+v", testCase.Expect, res)
+		}
+	}
+}
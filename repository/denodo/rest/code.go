@@ -1,32 +1,16 @@
 package rest
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
+	"quollio-reverse-agent/common/code"
 )
 
-type DenodoRestAPIError struct {
-	ErrorCode    int
-	ErrorMessage string
-}
-
-func (d *DenodoRestAPIError) Error() string {
-	return fmt.Sprintf("DenodoRestAPI Execution failed. Code: %v, Message: %s", d.ErrorCode, d.ErrorMessage)
-}
-
+// WrapError converts the response of the failed request into code.Error.
 func WrapError(data *http.Response) error {
-	denodoAPIError := DenodoRestAPIError{
-		ErrorCode:    data.StatusCode,
-		ErrorMessage: data.Status,
-	}
-	return &denodoAPIError
-}
-
-func GetErrorCode(err error) (int, error) {
-	var denodoErr *DenodoRestAPIError
-	if errors.As(err, &denodoErr) {
-		return denodoErr.ErrorCode, nil
+	operation := "Request"
+	if data.Request != nil && data.Request.URL != nil {
+		operation = fmt.Sprintf("%s %s", data.Request.Method, data.Request.URL.Path)
 	}
-	return 0, fmt.Errorf("Error is not of type DenodoRestAPIError")
+	return code.FromHTTPStatus("denodo", operation, data.StatusCode, fmt.Sprintf("DenodoRestAPI Execution failed. Code: %v, Message: %s", data.StatusCode, data.Status))
 }
@@ -1,19 +1,24 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
+	"quollio-reverse-agent/common/code"
 	"testing"
 )
 
-func TestGetErrorCode(t *testing.T) {
+func TestWrapError(t *testing.T) {
 	res := http.Response{
-		StatusCode: 401,
-		Status:     "Unauthorized",
+		StatusCode: 403,
+		Status:     "Forbidden",
 	}
 
 	err := WrapError(&res)
-	errorCode, extractErr := GetErrorCode(err)
-	if errorCode != 401 && extractErr == nil {
-		t.Errorf("GetErrorCode failed. Expect: %s but got %v", "401", errorCode)
+	var denodoErr *code.Error
+	if !errors.As(err, &denodoErr) {
+		t.Fatalf("want code.Error but got %v", err)
+	}
+	if denodoErr.Status != 403 || denodoErr.Category != code.Permission {
+		t.Errorf("WrapError failed. Expect: %d %s but got %d %s", 403, code.Permission, denodoErr.Status, denodoErr.Category)
 	}
 }
@@ -106,6 +106,18 @@ type UpdateLocalViewFieldInput struct {
 	ViewName         string `json:"viewName"`
 }
 
+type UpdateLocalViewLogicalNameInput struct {
+	ID          int    `json:"id"`
+	LogicalName string `json:"logicalName"`
+}
+
+type UpdateLocalViewFieldLogicalNameInput struct {
+	DatabaseName     string `json:"databaseName"`
+	FieldLogicalName string `json:"fieldLogicalName"`
+	FieldName        string `json:"fieldName"`
+	ViewName         string `json:"viewName"`
+}
+
 type FieldCapabilities struct {
 	FieldName    string   `json:"fieldName"`
 	Operators    []string `json:"operators"`
@@ -158,12 +170,12 @@ type SearchForm struct {
 
 type Schema struct {
 	Name            string      `json:"name"`
-	LogicalName     interface{} `json:"logicalName"`
+	LogicalName     string      `json:"logicalName"`
 	Type            string      `json:"type"`
 	Description     string      `json:"description"`
 	DescriptionType interface{} `json:"descriptionType"`
 	InLocal         bool        `json:"inLocal"`
-	Tags            interface{} `json:"tags"`
+	Tags            []Tag       `json:"tags"`
 	PrimaryKey      bool        `json:"primaryKey"`
 	Nullable        bool        `json:"nullable"`
 	SourceType      string      `json:"sourceType"`
@@ -204,6 +216,7 @@ type ViewStatisticsInfo struct {
 type ViewDetail struct {
 	Id                 int                `json:"id"`
 	Name               string             `json:"name"`
+	LogicalName        string             `json:"logicalName"`
 	DatabaseName       string             `json:"databaseName"`
 	Schema             []Schema           `json:"schema"`
 	TotalFields        int                `json:"totalFields"`
@@ -211,8 +224,8 @@ type ViewDetail struct {
 	ReadPermission     bool               `json:"readPermission"`
 	InLocal            bool               `json:"inLocal"`
 	InVDP              bool               `json:"inVDP"`
-	Tags               []interface{}      `json:"tags"`
-	Categories         []interface{}      `json:"categories"`
+	Tags               []Tag              `json:"tags"`
+	Categories         []Category         `json:"categories"`
 	Endorsements       interface{}        `json:"endorsements"`
 	Warnings           interface{}        `json:"warnings"`
 	Deprecations       interface{}        `json:"deprecations"`
@@ -232,15 +245,70 @@ type ViewDetail struct {
 
 type ViewColumn struct {
 	Name            string      `json:"name"`
-	LogicalName     interface{} `json:"logicalName"`
+	LogicalName     string      `json:"logicalName"`
 	Type            string      `json:"type"`
 	Description     string      `json:"description"`
 	DescriptionType interface{} `json:"descriptionType"`
 	InLocal         bool        `json:"inLocal"`
-	Tags            interface{} `json:"tags"`
+	Tags            []Tag       `json:"tags"`
 	PrimaryKey      bool        `json:"primaryKey"`
 	Nullable        bool        `json:"nullable"`
 	SourceType      string      `json:"sourceType"`
 	TypeSize        int         `json:"typeSize"`
 	TypeDecimal     int         `json:"typeDecimal"`
 }
+
+type Tag struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type TagInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Category struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    int    `json:"parentId"`
+}
+
+type CategoryInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateLocalViewFieldTagsInput struct {
+	DatabaseName string `json:"databaseName"`
+	ViewName     string `json:"viewName"`
+	FieldName    string `json:"fieldName"`
+	TagIDs       []int  `json:"tagIds"`
+}
+
+type PropertyGroup struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Properties  []Property `json:"properties"`
+}
+
+type Property struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+type PropertyInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+type PropertyValue struct {
+	PropertyID int    `json:"propertyId"`
+	Value      string `json:"value"`
+}
@@ -45,12 +45,39 @@ func (d *DenodoRepo) SendRequest(reqType, url string, data []byte) (*http.Respon
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Request failed with status: %v\n", resp.Status)
+	// MEMO: POST endpoints return 201 Created and some PUT endpoints return 204 No Content.
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close()
+		return nil, WrapError(resp)
 	}
 	return resp, nil
 }
 
+// sendJSON sends the input as JSON and decodes the response into the output. input and output can be nil.
+func (d *DenodoRepo) sendJSON(reqType, url string, input, output interface{}) error {
+	var inputBytes []byte
+	if input != nil {
+		b, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		inputBytes = b
+	}
+	res, err := d.SendRequest(reqType, url, inputBytes)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if output == nil {
+		return nil
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, output)
+}
+
 func (d *DenodoRepo) GetLocalDatabases() ([]models.Database, error) {
 	url := fmt.Sprintf("%s/public/api/database-management/local/databases", d.BaseURL)
 	res, err := d.SendRequest("GET", url, nil)
@@ -193,3 +220,92 @@ func (d *DenodoRepo) UpdateLocalViewFieldDescription(input models.UpdateLocalVie
 
 	return nil
 }
+
+func (d *DenodoRepo) UpdateLocalViewLogicalName(input models.UpdateLocalViewLogicalNameInput) error {
+	url := fmt.Sprintf("%s/public/api/views/logical-name", d.BaseURL)
+	return d.sendJSON("PUT", url, input, nil)
+}
+
+func (d *DenodoRepo) UpdateLocalViewFieldLogicalName(input models.UpdateLocalViewFieldLogicalNameInput) error {
+	url := fmt.Sprintf("%s/public/api/views/fields/logical-name", d.BaseURL)
+	return d.sendJSON("PUT", url, input, nil)
+}
+
+func (d *DenodoRepo) GetTags() ([]models.Tag, error) {
+	url := fmt.Sprintf("%s/public/api/tags", d.BaseURL)
+	var tags []models.Tag
+	if err := d.sendJSON("GET", url, nil, &tags); err != nil {
+		return []models.Tag{}, err
+	}
+	return tags, nil
+}
+
+func (d *DenodoRepo) CreateTag(input models.TagInput) (models.Tag, error) {
+	url := fmt.Sprintf("%s/public/api/tags", d.BaseURL)
+	var tag models.Tag
+	if err := d.sendJSON("POST", url, input, &tag); err != nil {
+		return models.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (d *DenodoRepo) GetCategories() ([]models.Category, error) {
+	url := fmt.Sprintf("%s/public/api/categories", d.BaseURL)
+	var categories []models.Category
+	if err := d.sendJSON("GET", url, nil, &categories); err != nil {
+		return []models.Category{}, err
+	}
+	return categories, nil
+}
+
+func (d *DenodoRepo) CreateCategory(input models.CategoryInput) (models.Category, error) {
+	url := fmt.Sprintf("%s/public/api/categories", d.BaseURL)
+	var category models.Category
+	if err := d.sendJSON("POST", url, input, &category); err != nil {
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// UpdateLocalViewTags replaces the tags of the view with the given tags.
+func (d *DenodoRepo) UpdateLocalViewTags(viewID int, tagIDs []int) error {
+	url := fmt.Sprintf("%s/public/api/views/%d/tags", d.BaseURL, viewID)
+	return d.sendJSON("PUT", url, tagIDs, nil)
+}
+
+// UpdateLocalViewCategories replaces the categories of the view with the given categories.
+func (d *DenodoRepo) UpdateLocalViewCategories(viewID int, categoryIDs []int) error {
+	url := fmt.Sprintf("%s/public/api/views/%d/categories", d.BaseURL, viewID)
+	return d.sendJSON("PUT", url, categoryIDs, nil)
+}
+
+// UpdateLocalViewFieldTags replaces the tags of the field with the given tags.
+func (d *DenodoRepo) UpdateLocalViewFieldTags(input models.UpdateLocalViewFieldTagsInput) error {
+	url := fmt.Sprintf("%s/public/api/views/fields/tags", d.BaseURL)
+	return d.sendJSON("PUT", url, input, nil)
+}
+
+// GetPropertyGroups returns the groups of the custom properties with their properties.
+func (d *DenodoRepo) GetPropertyGroups() ([]models.PropertyGroup, error) {
+	url := fmt.Sprintf("%s/public/api/property-groups", d.BaseURL)
+	var propertyGroups []models.PropertyGroup
+	if err := d.sendJSON("GET", url, nil, &propertyGroups); err != nil {
+		return []models.PropertyGroup{}, err
+	}
+	return propertyGroups, nil
+}
+
+func (d *DenodoRepo) CreateProperty(propertyGroupID int, input models.PropertyInput) (models.Property, error) {
+	url := fmt.Sprintf("%s/public/api/property-groups/%d/properties", d.BaseURL, propertyGroupID)
+	var property models.Property
+	if err := d.sendJSON("POST", url, input, &property); err != nil {
+		return models.Property{}, err
+	}
+	return property, nil
+}
+
+// UpdateLocalViewPropertyValues sets the values of the custom properties of the view.
+func (d *DenodoRepo) UpdateLocalViewPropertyValues(viewID int, values []models.PropertyValue) error {
+	url := fmt.Sprintf("%s/public/api/views/%d/property-values", d.BaseURL, viewID)
+	return d.sendJSON("PUT", url, values, nil)
+}
@@ -2,42 +2,45 @@ package glue
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"quollio-reverse-agent/repository/glue/code"
-	"strings"
+	"quollio-reverse-agent/common/code"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/glue"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
-
-	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 )
 
 type GlueClient struct {
 	GlueClient *glue.Client
+	Region     string
 }
 
-func NewGlueClient(roleARN string, profileName string) (GlueClient, error) {
+// DefaultRegion is used when the region isn't specified.
+const DefaultRegion = "ap-northeast-1"
+
+func NewGlueClient(roleARN, profileName, region string) (GlueClient, error) {
+	if region == "" {
+		region = DefaultRegion
+	}
 	switch profileName {
 	case "":
 		cfg, err := config.LoadDefaultConfig(
 			context.TODO(),
-			config.WithRegion("ap-northeast-1"),
+			config.WithRegion(region),
 		)
 		if err != nil {
 			return GlueClient{}, err
 		}
 		glueClient := GlueClient{
 			GlueClient: returnGlueClient(cfg, roleARN),
+			Region:     cfg.Region,
 		}
 		return glueClient, nil
 	default:
 		cfg, err := config.LoadDefaultConfig(
 			context.TODO(),
-			config.WithRegion("ap-northeast-1"),
+			config.WithRegion(region),
 			config.WithSharedConfigProfile(profileName),
 		)
 		if err != nil {
@@ -45,6 +48,7 @@ func NewGlueClient(roleARN string, profileName string) (GlueClient, error) {
 		}
 		glueClient := GlueClient{
 			GlueClient: returnGlueClient(cfg, roleARN),
+			Region:     cfg.Region,
 		}
 		return glueClient, nil
 	}
@@ -67,30 +71,7 @@ func (g *GlueClient) GetDatabases(accountID, nextToken string) (*glue.GetDatabas
 	}
 	dbs, err := g.GlueClient.GetDatabases(ctx, &glueDBsInput)
 	if err != nil {
-		var re *awsHttp.ResponseError
-		if errors.As(err, &re) {
-			switch {
-			case strings.Contains(re.Err.Error(), "InvalidGrantException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.NOT_AUTHORIZED,
-					Message:     fmt.Sprintf("Failed to glue.GetDatabases. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			case strings.Contains(re.Err.Error(), "EntityNotFoundException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.RESOURCE_NOT_FOUND,
-					Message:     fmt.Sprintf("Failed to glue.GetDatabases. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			default:
-				return nil, err
-			}
-		}
-		return nil, err
+		return nil, code.FromAWS("glue", "GetDatabases", err)
 	}
 	return dbs, nil
 }
@@ -99,34 +80,24 @@ func (g *GlueClient) UpdateDatabase(updateDatabaseInput glue.UpdateDatabaseInput
 	ctx := context.Background()
 	output, err := g.GlueClient.UpdateDatabase(ctx, &updateDatabaseInput)
 	if err != nil {
-		var re *awsHttp.ResponseError
-		if errors.As(err, &re) {
-			switch {
-			case strings.Contains(re.Err.Error(), "InvalidGrantException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.NOT_AUTHORIZED,
-					Message:     fmt.Sprintf("Failed to glue.UpdateDatabase. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			case strings.Contains(re.Err.Error(), "EntityNotFoundException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.RESOURCE_NOT_FOUND,
-					Message:     fmt.Sprintf("Failed to glue.UpdateDatabase. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			default:
-				return nil, err
-			}
-		}
-		return nil, err
+		return nil, code.FromAWS("glue", "UpdateDatabase", err)
 	}
 	return output, nil
 }
 
+func (g *GlueClient) GetDatabase(catalogID, dbName string) (*glue.GetDatabaseOutput, error) {
+	ctx := context.Background()
+	glueDatabaseInput := glue.GetDatabaseInput{
+		CatalogId: &catalogID,
+		Name:      &dbName,
+	}
+	database, err := g.GlueClient.GetDatabase(ctx, &glueDatabaseInput)
+	if err != nil {
+		return nil, code.FromAWS("glue", "GetDatabase", err)
+	}
+	return database, nil
+}
+
 func (g *GlueClient) GetTable(catalogID, dbName, tableName string) (*glue.GetTableOutput, error) {
 	ctx := context.Background()
 	glueTableInput := glue.GetTableInput{
@@ -136,63 +107,86 @@ func (g *GlueClient) GetTable(catalogID, dbName, tableName string) (*glue.GetTab
 	}
 	table, err := g.GlueClient.GetTable(ctx, &glueTableInput)
 	if err != nil {
-		var re *awsHttp.ResponseError
-		if errors.As(err, &re) {
-			switch {
-			case strings.Contains(re.Err.Error(), "InvalidGrantException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.NOT_AUTHORIZED,
-					Message:     fmt.Sprintf("Failed to glue.GetTable. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			case strings.Contains(re.Err.Error(), "EntityNotFoundException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.RESOURCE_NOT_FOUND,
-					Message:     fmt.Sprintf("Failed to glue.GetTable. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			default:
-				return nil, err
-			}
-		}
-		return nil, err
+		return nil, code.FromAWS("glue", "GetTable", err)
 	}
 	return table, nil
 }
 
+// GetTables returns a page of the tables in the database.
+func (g *GlueClient) GetTables(catalogID, dbName, nextToken string) (*glue.GetTablesOutput, error) {
+	ctx := context.Background()
+	glueTablesInput := glue.GetTablesInput{
+		CatalogId:    &catalogID,
+		DatabaseName: &dbName,
+		MaxResults:   aws.Int32(100),
+	}
+	if nextToken != "" {
+		glueTablesInput.NextToken = &nextToken
+	}
+	tables, err := g.GlueClient.GetTables(ctx, &glueTablesInput)
+	if err != nil {
+		return nil, code.FromAWS("glue", "GetTables", err)
+	}
+	return tables, nil
+}
+
+// GetTableVersions returns a page of the versions of the table.
+func (g *GlueClient) GetTableVersions(catalogID, dbName, tableName, nextToken string) (*glue.GetTableVersionsOutput, error) {
+	ctx := context.Background()
+	glueTableVersionsInput := glue.GetTableVersionsInput{
+		CatalogId:    &catalogID,
+		DatabaseName: &dbName,
+		TableName:    &tableName,
+		MaxResults:   aws.Int32(100),
+	}
+	if nextToken != "" {
+		glueTableVersionsInput.NextToken = &nextToken
+	}
+	versions, err := g.GlueClient.GetTableVersions(ctx, &glueTableVersionsInput)
+	if err != nil {
+		return nil, code.FromAWS("glue", "GetTableVersions", err)
+	}
+	return versions, nil
+}
+
+// GetTableVersion returns the version of the table.
+func (g *GlueClient) GetTableVersion(catalogID, dbName, tableName, versionID string) (*glue.GetTableVersionOutput, error) {
+	ctx := context.Background()
+	glueTableVersionInput := glue.GetTableVersionInput{
+		CatalogId:    &catalogID,
+		DatabaseName: &dbName,
+		TableName:    &tableName,
+		VersionId:    &versionID,
+	}
+	version, err := g.GlueClient.GetTableVersion(ctx, &glueTableVersionInput)
+	if err != nil {
+		return nil, code.FromAWS("glue", "GetTableVersion", err)
+	}
+	return version, nil
+}
+
+// BatchDeleteTableVersion deletes the versions of the table. Up to 100 versions can be deleted at once.
+func (g *GlueClient) BatchDeleteTableVersion(catalogID, dbName, tableName string, versionIDs []string) (*glue.BatchDeleteTableVersionOutput, error) {
+	ctx := context.Background()
+	batchDeleteTableVersionInput := glue.BatchDeleteTableVersionInput{
+		CatalogId:    &catalogID,
+		DatabaseName: &dbName,
+		TableName:    &tableName,
+		VersionIds:   versionIDs,
+	}
+	output, err := g.GlueClient.BatchDeleteTableVersion(ctx, &batchDeleteTableVersionInput)
+	if err != nil {
+		return nil, code.FromAWS("glue", "BatchDeleteTableVersion", err)
+	}
+	return output, nil
+}
+
 func (g *GlueClient) UpdateTable(catalogID, dbName string, uti glue.UpdateTableInput) (*glue.UpdateTableOutput, error) {
 	ctx := context.Background()
 
 	output, err := g.GlueClient.UpdateTable(ctx, &uti)
 	if err != nil {
-		var re *awsHttp.ResponseError
-		if errors.As(err, &re) {
-			switch {
-			case strings.Contains(re.Err.Error(), "InvalidGrantException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.NOT_AUTHORIZED,
-					Message:     fmt.Sprintf("Failed to glue.UpdateTable Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			case strings.Contains(re.Err.Error(), "EntityNotFoundException:"):
-				ge := code.GlueError{
-					Number:      re.HTTPStatusCode(),
-					ErrorReason: code.RESOURCE_NOT_FOUND,
-					Message:     fmt.Sprintf("Failed to glue.UpdateTable. Error %s. %s", re.Err.Error(), err.Error()),
-					Err:         re,
-				}
-				return nil, &ge
-			default:
-				return nil, err
-			}
-		}
-		return nil, err
+		return nil, code.FromAWS("glue", "UpdateTable", err)
 	}
 	return output, nil
 }
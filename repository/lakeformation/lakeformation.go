@@ -0,0 +1,135 @@
+package lakeformation
+
+import (
+	"context"
+	"fmt"
+	"quollio-reverse-agent/common/code"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/lakeformation"
+	"github.com/aws/aws-sdk-go-v2/service/lakeformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type LakeFormationClient struct {
+	LakeFormationClient *lakeformation.Client
+}
+
+func NewLakeFormationClient(roleARN, profileName, region string) (LakeFormationClient, error) {
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if profileName != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profileName))
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return LakeFormationClient{}, err
+	}
+	stsSvc := sts.NewFromConfig(cfg)
+	creds := stscreds.NewAssumeRoleProvider(stsSvc, roleARN)
+	cfg.Credentials = aws.NewCredentialsCache(creds)
+	return LakeFormationClient{
+		LakeFormationClient: lakeformation.NewFromConfig(cfg),
+	}, nil
+}
+
+// GetLFTag returns nil without an error if the LF-Tag doesn't exist.
+func (l *LakeFormationClient) GetLFTag(catalogID, tagKey string) (*lakeformation.GetLFTagOutput, error) {
+	ctx := context.Background()
+	output, err := l.LakeFormationClient.GetLFTag(ctx, &lakeformation.GetLFTagInput{
+		CatalogId: &catalogID,
+		TagKey:    &tagKey,
+	})
+	err = code.FromAWS("lakeformation", "GetLFTag", err)
+	if code.Is(err, code.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (l *LakeFormationClient) CreateLFTag(catalogID, tagKey string, tagValues []string) error {
+	ctx := context.Background()
+	_, err := l.LakeFormationClient.CreateLFTag(ctx, &lakeformation.CreateLFTagInput{
+		CatalogId: &catalogID,
+		TagKey:    &tagKey,
+		TagValues: tagValues,
+	})
+	return code.FromAWS("lakeformation", "CreateLFTag", err)
+}
+
+func (l *LakeFormationClient) AddLFTagValues(catalogID, tagKey string, tagValues []string) error {
+	ctx := context.Background()
+	_, err := l.LakeFormationClient.UpdateLFTag(ctx, &lakeformation.UpdateLFTagInput{
+		CatalogId:      &catalogID,
+		TagKey:         &tagKey,
+		TagValuesToAdd: tagValues,
+	})
+	return code.FromAWS("lakeformation", "UpdateLFTag", err)
+}
+
+// GetResourceLFTags returns the LF-Tags assigned to the resource directly. The inherited LF-Tags are not included.
+func (l *LakeFormationClient) GetResourceLFTags(catalogID string, resource *types.Resource) (*lakeformation.GetResourceLFTagsOutput, error) {
+	ctx := context.Background()
+	output, err := l.LakeFormationClient.GetResourceLFTags(ctx, &lakeformation.GetResourceLFTagsInput{
+		CatalogId:          &catalogID,
+		Resource:           resource,
+		ShowAssignedLFTags: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, code.FromAWS("lakeformation", "GetResourceLFTags", err)
+	}
+	return output, nil
+}
+
+func (l *LakeFormationClient) AddLFTagsToResource(catalogID string, resource *types.Resource, lfTags []types.LFTagPair) error {
+	ctx := context.Background()
+	output, err := l.LakeFormationClient.AddLFTagsToResource(ctx, &lakeformation.AddLFTagsToResourceInput{
+		CatalogId: &catalogID,
+		Resource:  resource,
+		LFTags:    lfTags,
+	})
+	if err != nil {
+		return code.FromAWS("lakeformation", "AddLFTagsToResource", err)
+	}
+	return genFailureError("AddLFTagsToResource", output.Failures)
+}
+
+func (l *LakeFormationClient) RemoveLFTagsFromResource(catalogID string, resource *types.Resource, lfTags []types.LFTagPair) error {
+	ctx := context.Background()
+	output, err := l.LakeFormationClient.RemoveLFTagsFromResource(ctx, &lakeformation.RemoveLFTagsFromResourceInput{
+		CatalogId: &catalogID,
+		Resource:  resource,
+		LFTags:    lfTags,
+	})
+	if err != nil {
+		return code.FromAWS("lakeformation", "RemoveLFTagsFromResource", err)
+	}
+	return genFailureError("RemoveLFTagsFromResource", output.Failures)
+}
+
+// genFailureError converts the failures of the LF-Tag operation into an error. It returns nil if there is no failure.
+func genFailureError(operation string, failures []types.LFTagError) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	failure := failures[0]
+	var tagKey, errorCode, message string
+	if failure.LFTag != nil {
+		tagKey = aws.ToString(failure.LFTag.TagKey)
+	}
+	if failure.Error != nil {
+		errorCode = aws.ToString(failure.Error.ErrorCode)
+		message = aws.ToString(failure.Error.ErrorMessage)
+	}
+	return &code.Error{
+		Service:   "lakeformation",
+		Operation: operation,
+		Category:  code.CategoryOfAWSCode(errorCode),
+		Code:      errorCode,
+		Message:   fmt.Sprintf("%d failures. key %s: %s", len(failures), tagKey, message),
+	}
+}
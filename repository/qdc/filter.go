@@ -0,0 +1,587 @@
+package qdc
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AssetFilter selects the QDIC assets that connectors are allowed to touch.
+// An asset is a target when it matches the include expression (or no include expression is given)
+// and does not match the exclude expression.
+//
+// Expression examples:
+//
+//	path.schema3 glob "sales_*" and not is_archived == true
+//	service_name == "bigquery" and updated_at >= "2024-04-01"
+//	tag in ("tag-1234", "tag-5678") or created_by =~ "^etl-"
+type AssetFilter struct {
+	include filterExpr
+	exclude filterExpr
+	// includeByPath is include with the conditions on the fields other than path.<layer> left unknown.
+	includeByPath filterExpr
+}
+
+// MEMO: Conditions on a path layer that the asset doesn't have (e.g. path.table on a schema asset) evaluate to unknown.
+// Unknown doesn't drop an asset by include, and doesn't drop it by exclude either.
+type filterResult int
+
+const (
+	filterFalse filterResult = iota
+	filterTrue
+	filterUnknown
+)
+
+type filterExpr interface {
+	eval(asset Data) filterResult
+}
+
+func NewAssetFilter(include, exclude string) (AssetFilter, error) {
+	var filter AssetFilter
+	var err error
+	if strings.TrimSpace(include) != "" {
+		filter.include, err = parseFilterExpr(include)
+		if err != nil {
+			return AssetFilter{}, fmt.Errorf("Failed to parse include expression. %s", err.Error())
+		}
+		filter.includeByPath = onlyPathConditions(filter.include)
+	}
+	if strings.TrimSpace(exclude) != "" {
+		filter.exclude, err = parseFilterExpr(exclude)
+		if err != nil {
+			return AssetFilter{}, fmt.Errorf("Failed to parse exclude expression. %s", err.Error())
+		}
+	}
+	return filter, nil
+}
+
+func (f AssetFilter) IsTarget(asset Data) bool {
+	if f.include != nil && f.include.eval(asset) == filterFalse {
+		return false
+	}
+	if f.exclude != nil && f.exclude.eval(asset) == filterTrue {
+		return false
+	}
+	return true
+}
+
+func (f AssetFilter) FilterAssets(assets []Data) []Data {
+	if f.include == nil && f.exclude == nil {
+		return assets
+	}
+	var targetAssets []Data
+	for _, asset := range assets {
+		if f.IsTarget(asset) {
+			targetAssets = append(targetAssets, asset)
+		}
+	}
+	return targetAssets
+}
+
+// IsInScope reports whether the asset or its descendants can be targets. It's used for the parents whose children are read,
+// and for the children of a target asset. The include conditions on the fields other than path.<layer> may target another
+// level (e.g. physical_name of a table), so they don't drop the asset. An asset that matches exclude is out of scope with its descendants.
+func (f AssetFilter) IsInScope(asset Data) bool {
+	if f.includeByPath != nil && f.includeByPath.eval(asset) == filterFalse {
+		return false
+	}
+	if f.exclude != nil && f.exclude.eval(asset) == filterTrue {
+		return false
+	}
+	return true
+}
+
+func (f AssetFilter) FilterAssetsInScope(assets []Data) []Data {
+	if f.include == nil && f.exclude == nil {
+		return assets
+	}
+	var scopedAssets []Data
+	for _, asset := range assets {
+		if f.IsInScope(asset) {
+			scopedAssets = append(scopedAssets, asset)
+		}
+	}
+	return scopedAssets
+}
+
+// onlyPathConditions returns the expression whose conditions on the fields other than path.<layer> evaluate to unknown.
+// The path layers are shared by the descendants, while the other fields are evaluated on each level.
+func onlyPathConditions(expr filterExpr) filterExpr {
+	switch e := expr.(type) {
+	case notExpr:
+		return notExpr{expr: onlyPathConditions(e.expr)}
+	case andExpr:
+		return andExpr{left: onlyPathConditions(e.left), right: onlyPathConditions(e.right)}
+	case orExpr:
+		return orExpr{left: onlyPathConditions(e.left), right: onlyPathConditions(e.right)}
+	case compareExpr:
+		if strings.HasPrefix(e.field, "path.") {
+			return e
+		}
+	}
+	return unknownExpr{}
+}
+
+type unknownExpr struct{}
+
+func (unknownExpr) eval(asset Data) filterResult {
+	return filterUnknown
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (n notExpr) eval(asset Data) filterResult {
+	switch n.expr.eval(asset) {
+	case filterTrue:
+		return filterFalse
+	case filterFalse:
+		return filterTrue
+	default:
+		return filterUnknown
+	}
+}
+
+type andExpr struct {
+	left, right filterExpr
+}
+
+func (a andExpr) eval(asset Data) filterResult {
+	left := a.left.eval(asset)
+	if left == filterFalse {
+		return filterFalse
+	}
+	right := a.right.eval(asset)
+	switch {
+	case right == filterFalse:
+		return filterFalse
+	case left == filterTrue && right == filterTrue:
+		return filterTrue
+	default:
+		return filterUnknown
+	}
+}
+
+type orExpr struct {
+	left, right filterExpr
+}
+
+func (o orExpr) eval(asset Data) filterResult {
+	left := o.left.eval(asset)
+	if left == filterTrue {
+		return filterTrue
+	}
+	right := o.right.eval(asset)
+	switch {
+	case right == filterTrue:
+		return filterTrue
+	case left == filterFalse && right == filterFalse:
+		return filterFalse
+	default:
+		return filterUnknown
+	}
+}
+
+type compareExpr struct {
+	field    string
+	operator string
+	values   []string
+	pattern  *regexp.Regexp
+	time     time.Time
+}
+
+func (c compareExpr) eval(asset Data) filterResult {
+	switch {
+	case c.field == "updated_at" || c.field == "created_at":
+		target := asset.UpdatedAt
+		if c.field == "created_at" {
+			target = asset.CreatedAt
+		}
+		return toFilterResult(compareTime(c.operator, target, c.time))
+	case c.field == "is_archived":
+		return toFilterResult(c.matchAny([]string{fmt.Sprint(asset.IsArchived)}))
+	case c.field == "is_csv_imported":
+		return toFilterResult(c.matchAny([]string{fmt.Sprint(asset.IsCsvImported)}))
+	case c.field == "is_lost":
+		return toFilterResult(c.matchAny([]string{fmt.Sprint(asset.IsLost)}))
+	case strings.HasPrefix(c.field, "path."):
+		p := GetSpecifiedAssetFromPath(asset, strings.TrimPrefix(c.field, "path."))
+		if p.PathLayer == "" {
+			return filterUnknown
+		}
+		return toFilterResult(c.matchAny([]string{p.Name}))
+	default:
+		return toFilterResult(c.matchAny(getFilterFieldValues(asset, c.field)))
+	}
+}
+
+// matchAny reports whether the condition holds for the field values.
+// List fields such as updated_by and tag match if any of the values matches, and negative operators require that none matches.
+func (c compareExpr) matchAny(fieldValues []string) bool {
+	switch c.operator {
+	case "!=":
+		return !compareExpr{field: c.field, operator: "==", values: c.values}.matchAny(fieldValues)
+	case "!~":
+		return !compareExpr{field: c.field, operator: "=~", pattern: c.pattern}.matchAny(fieldValues)
+	}
+	for _, fieldValue := range fieldValues {
+		switch c.operator {
+		case "==", "in":
+			for _, value := range c.values {
+				if fieldValue == value {
+					return true
+				}
+			}
+		case "glob":
+			for _, value := range c.values {
+				// MEMO: The pattern is validated when the expression is parsed.
+				if matched, _ := path.Match(value, fieldValue); matched {
+					return true
+				}
+			}
+		case "=~":
+			if c.pattern.MatchString(fieldValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getFilterFieldValues(asset Data, field string) []string {
+	switch field {
+	case "id":
+		return []string{asset.ID}
+	case "object_type":
+		return []string{asset.ObjectType}
+	case "service_name":
+		return []string{asset.ServiceName}
+	case "physical_name":
+		return []string{asset.PhysicalName}
+	case "logical_name":
+		return []string{asset.LogicalName}
+	case "created_by":
+		return []string{asset.CreatedBy}
+	case "updated_by":
+		return asset.UpdatedBy
+	case "path":
+		var names []string
+		for _, p := range asset.Path {
+			names = append(names, p.Name)
+		}
+		return names
+	case "tag":
+		var tagIDs []string
+		for _, tag := range GetAllTagIds(asset) {
+			for _, tagID := range []string{tag.TagGroupId, tag.ParentTagId, tag.ChildTagId} {
+				if tagID != "" {
+					tagIDs = append(tagIDs, tagID)
+				}
+			}
+		}
+		return tagIDs
+	}
+	return nil
+}
+
+func compareTime(operator string, target, value time.Time) bool {
+	switch operator {
+	case "==":
+		return target.Equal(value)
+	case "!=":
+		return !target.Equal(value)
+	case "<":
+		return target.Before(value)
+	case "<=":
+		return !target.After(value)
+	case ">":
+		return target.After(value)
+	case ">=":
+		return !target.Before(value)
+	}
+	return false
+}
+
+func toFilterResult(b bool) filterResult {
+	if b {
+		return filterTrue
+	}
+	return filterFalse
+}
+
+var filterStringFields = []string{"id", "object_type", "service_name", "physical_name", "logical_name", "created_by", "updated_by", "path", "tag"}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func parseFilterExpr(input string) (filterExpr, error) {
+	tokens, err := tokenizeFilterExpr(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (filterExpr, error) {
+	if strings.EqualFold(p.peek(), "not") {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	if p.peek() == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, err := p.next(); err != nil || token != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return expr, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filterExpr, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field = strings.ToLower(field)
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator = strings.ToLower(operator)
+
+	var values []string
+	if operator == "in" {
+		if token, err := p.next(); err != nil || token != "(" {
+			return nil, fmt.Errorf("in requires a list like (\"a\", \"b\")")
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			token, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if token == ")" {
+				break
+			}
+			if token != "," {
+				return nil, fmt.Errorf("unexpected token %q in list", token)
+			}
+		}
+	} else {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = []string{value}
+	}
+
+	expr := compareExpr{field: field, operator: operator, values: values}
+	switch {
+	case field == "updated_at" || field == "created_at":
+		switch operator {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, fmt.Errorf("operator %s is not supported for %s", operator, field)
+		}
+		expr.time, err = parseFilterTime(values[0])
+		if err != nil {
+			return nil, err
+		}
+	case field == "is_archived" || field == "is_csv_imported" || field == "is_lost":
+		if operator != "==" && operator != "!=" {
+			return nil, fmt.Errorf("operator %s is not supported for %s", operator, field)
+		}
+		expr.values[0] = strings.ToLower(values[0])
+		if expr.values[0] != "true" && expr.values[0] != "false" {
+			return nil, fmt.Errorf("%s must be compared with true or false", field)
+		}
+	case strings.HasPrefix(field, "path.") || isFilterStringField(field):
+		switch operator {
+		case "==", "!=", "in":
+		case "glob":
+			if _, err := path.Match(values[0], ""); err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q", values[0])
+			}
+		case "=~", "!~":
+			expr.pattern, err = regexp.Compile(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q. %s", values[0], err.Error())
+			}
+		default:
+			return nil, fmt.Errorf("operator %s is not supported for %s", operator, field)
+		}
+	default:
+		return nil, fmt.Errorf("unknown field %s", field)
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseValue() (string, error) {
+	token, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(token, "\"") {
+		return token[1:], nil
+	}
+	switch token {
+	case "(", ")", ",":
+		return "", fmt.Errorf("unexpected token %q", token)
+	}
+	return token, nil
+}
+
+func isFilterStringField(field string) bool {
+	for _, f := range filterStringFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFilterTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q. use RFC3339 or YYYY-MM-DD", value)
+}
+
+// tokenizeFilterExpr splits the expression into tokens.
+// Quoted strings are returned with a leading `"` and without the closing quote so that they are never confused with keywords.
+func tokenizeFilterExpr(input string) ([]string, error) {
+	var tokens []string
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"' || r == '\'':
+			quote := r
+			var sb strings.Builder
+			sb.WriteRune('"')
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, sb.String())
+		case strings.ContainsRune("=!<>", r):
+			j := i + 1
+			if j < len(runes) && (runes[j] == '=' || runes[j] == '~') {
+				j++
+			}
+			operator := string(runes[i:j])
+			switch operator {
+			case "==", "!=", "=~", "!~", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("unknown operator %s", operator)
+			}
+			tokens = append(tokens, operator)
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()\",'=!<>", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,252 @@
+package qdc_test
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+	"time"
+)
+
+func TestAssetFilterIsTarget(t *testing.T) {
+	tableAsset := qdc.Data{
+		Path: []qdc.Path{
+			{
+				PathLayer:  "schema4",
+				ID:         "schm-1234",
+				ObjectType: "schema",
+				Name:       "test-project",
+			},
+			{
+				PathLayer:  "schema3",
+				ID:         "schm-5678",
+				ObjectType: "schema",
+				Name:       "sales_dataset",
+			},
+			{
+				PathLayer:  "table",
+				ID:         "tbl-1234",
+				ObjectType: "table",
+				Name:       "orders",
+			},
+		},
+		RuleTagIds: []qdc.RuleTagIds{
+			{
+				TagGroupId:  "tggr-1234",
+				ParentTagId: "tag-1234",
+			},
+		},
+		ManualTagIds: []qdc.RuleTagIds{
+			{
+				TagGroupId:  "tggr-5678",
+				ParentTagId: "tag-5678",
+				ChildTagId:  "tag-9012",
+			},
+		},
+		ID:            "tbl-1234",
+		ObjectType:    "table",
+		ServiceName:   "bigquery",
+		PhysicalName:  "orders",
+		CreatedBy:     "etl-user",
+		UpdatedBy:     []string{"user-a", "user-b"},
+		UpdatedAt:     time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC),
+		IsArchived:    false,
+		IsCsvImported: true,
+	}
+	schemaAsset := qdc.Data{
+		Path: []qdc.Path{
+			{
+				PathLayer:  "schema4",
+				ID:         "schm-1234",
+				ObjectType: "schema",
+				Name:       "test-project",
+			},
+			{
+				PathLayer:  "schema3",
+				ID:         "schm-5678",
+				ObjectType: "schema",
+				Name:       "sales_dataset",
+			},
+		},
+		ID:           "schm-5678",
+		ObjectType:   "schema",
+		ServiceName:  "bigquery",
+		PhysicalName: "sales_dataset",
+	}
+
+	testCases := []struct {
+		name    string
+		include string
+		exclude string
+		asset   qdc.Data
+		want    bool
+	}{
+		{
+			name:  "no expression",
+			asset: tableAsset,
+			want:  true,
+		},
+		{
+			name:    "glob on path layer",
+			include: `path.schema3 glob "sales_*"`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "glob on path layer does not match",
+			include: `path.schema3 glob "hr_*"`,
+			asset:   tableAsset,
+			want:    false,
+		},
+		{
+			name:    "missing path layer is not dropped by include",
+			include: `path.table == "orders"`,
+			asset:   schemaAsset,
+			want:    true,
+		},
+		{
+			name:    "missing path layer is not dropped by exclude",
+			exclude: `path.table == "orders"`,
+			asset:   schemaAsset,
+			want:    true,
+		},
+		{
+			name:    "regex on created_by",
+			include: `created_by =~ "^etl-"`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "updated_by matches any of the users",
+			include: `updated_by == "user-b"`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "updated_at range",
+			include: `updated_at >= "2024-04-01" and updated_at < "2024-05-01T00:00:00Z"`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "updated_at out of range",
+			include: `updated_at > "2024-04-10"`,
+			asset:   tableAsset,
+			want:    false,
+		},
+		{
+			name:    "tag in list",
+			include: `tag in ("tag-0000", 'tag-9012')`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "boolean fields with not and parenthesis",
+			include: `service_name == "bigquery" and not (is_archived == true or is_csv_imported == false)`,
+			asset:   tableAsset,
+			want:    true,
+		},
+		{
+			name:    "exclude wins over include",
+			include: `path.schema3 glob "sales_*"`,
+			exclude: `is_csv_imported == TRUE`,
+			asset:   tableAsset,
+			want:    false,
+		},
+		{
+			name:    "negative operator on list field",
+			include: `updated_by != "user-a"`,
+			asset:   tableAsset,
+			want:    false,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := qdc.NewAssetFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewAssetFilter failed. %s", err.Error())
+			}
+			res := filter.IsTarget(tt.asset)
+			if res != tt.want {
+				t.Errorf("want %v but got %v.", tt.want, res)
+			}
+		})
+	}
+}
+
+func TestNewAssetFilterInvalidExpression(t *testing.T) {
+	testCases := []string{
+		`unknown_field == "a"`,
+		`physical_name == `,
+		`physical_name = "a"`,
+		`(physical_name == "a"`,
+		`physical_name =~ "["`,
+		`updated_at >= "yesterday"`,
+		`is_archived == "maybe"`,
+		`updated_at glob "2024-*"`,
+		`physical_name == "a" physical_name == "b"`,
+		`physical_name == "a`,
+	}
+	for _, testCase := range testCases {
+		_, err := qdc.NewAssetFilter(testCase, "")
+		if err == nil {
+			t.Errorf("expected an error for %s but got nil.", testCase)
+		}
+	}
+}
+
+func TestAssetFilterFilterAssets(t *testing.T) {
+	assets := []qdc.Data{
+		{ID: "tbl-1", PhysicalName: "orders"},
+		{ID: "tbl-2", PhysicalName: "customers"},
+		{ID: "tbl-3", PhysicalName: "order_items"},
+	}
+	filter, err := qdc.NewAssetFilter(`physical_name glob "order*"`, `physical_name == "order_items"`)
+	if err != nil {
+		t.Fatalf("NewAssetFilter failed. %s", err.Error())
+	}
+	res := filter.FilterAssets(assets)
+	if len(res) != 1 || res[0].ID != "tbl-1" {
+		t.Errorf("want only tbl-1 but got %v.", res)
+	}
+}
+
+func TestAssetFilterIsInScope(t *testing.T) {
+	schemaAsset := qdc.Data{
+		Path: []qdc.Path{
+			{PathLayer: "schema3", Name: "sales_dataset"},
+		},
+		PhysicalName: "sales_dataset",
+	}
+	columnAsset := qdc.Data{
+		Path: []qdc.Path{
+			{PathLayer: "schema3", Name: "sales_dataset"},
+			{PathLayer: "table", Name: "orders"},
+			{PathLayer: "column", Name: "secret_token"},
+		},
+		PhysicalName: "secret_token",
+	}
+	testCases := []struct {
+		name    string
+		include string
+		exclude string
+		asset   qdc.Data
+		want    bool
+	}{
+		{name: "include on a table name keeps the schema", include: `physical_name == "orders"`, asset: schemaAsset, want: true},
+		{name: "include on a table name keeps the columns", include: `physical_name == "orders"`, asset: columnAsset, want: true},
+		{name: "include on another schema drops the schema", include: `path.schema3 glob "finance_*"`, asset: schemaAsset, want: false},
+		{name: "include with a path and a name", include: `path.schema3 glob "sales_*" and physical_name == "orders"`, asset: schemaAsset, want: true},
+		{name: "exclude drops the schema", exclude: `physical_name == "sales_dataset"`, asset: schemaAsset, want: false},
+		{name: "exclude on a column path", exclude: `path.column glob "secret_*"`, asset: columnAsset, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := qdc.NewAssetFilter(tc.include, tc.exclude)
+			if err != nil {
+				t.Fatalf("NewAssetFilter failed. %s", err.Error())
+			}
+			if got := filter.IsInScope(tc.asset); got != tc.want {
+				t.Errorf("want %v but got %v", tc.want, got)
+			}
+		})
+	}
+}
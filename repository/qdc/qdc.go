@@ -23,6 +23,8 @@ type QDCExternalAPI struct {
 	HttpClient   *http.Client
 	AccessToken  string
 	Logger       *logger.BuiltinLogger
+
+	tagDictionary *TagDictionary
 }
 
 type QDCTokenResponse struct {
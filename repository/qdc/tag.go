@@ -0,0 +1,87 @@
+package qdc
+
+import (
+	"fmt"
+	"slices"
+)
+
+// TagSelector selects assets by QDIC tags. Empty fields match any value.
+// TagID is compared with both of the parent tag and the child tag.
+// The tag group and the tag can also be given by name. The names are looked up by ResolveTagNames before Matches,
+// and the names that are not resolved match nothing.
+type TagSelector struct {
+	TagGroupID   string `json:"tag_group_id"`
+	TagID        string `json:"tag_id"`
+	TagGroupName string `json:"tag_group_name"`
+	TagName      string `json:"tag_name"`
+
+	tagGroupIDs []string
+	tagIDs      []string
+}
+
+func (s TagSelector) IsEmpty() bool {
+	return s.TagGroupID == "" && s.TagID == "" && s.TagGroupName == "" && s.TagName == ""
+}
+
+// HasNames returns true if the tag group or the tag is given by name.
+func (s TagSelector) HasNames() bool {
+	return s.TagGroupName != "" || s.TagName != ""
+}
+
+// Matches returns true if any of the rule tags or the manual tags of the asset is selected.
+func (s TagSelector) Matches(asset Data) bool {
+	if s.IsEmpty() {
+		return false
+	}
+	for _, tag := range GetAllTagIds(asset) {
+		if s.TagGroupID != "" && tag.TagGroupId != s.TagGroupID {
+			continue
+		}
+		if s.TagID != "" && tag.ParentTagId != s.TagID && tag.ChildTagId != s.TagID {
+			continue
+		}
+		if s.TagGroupName != "" && !slices.Contains(s.tagGroupIDs, tag.TagGroupId) {
+			continue
+		}
+		if s.TagName != "" && !slices.Contains(s.tagIDs, tag.ParentTagId) && !slices.Contains(s.tagIDs, tag.ChildTagId) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ResolveTagNames looks up the IDs of the tag groups and the tags given by name in the selectors.
+// An error is returned if a name is not in the dictionary.
+func ResolveTagNames(tagDictionary TagDictionary, selectors []*TagSelector) error {
+	for _, s := range selectors {
+		if s.TagGroupName != "" {
+			s.tagGroupIDs = tagDictionary.FindTagGroupIDs(s.TagGroupName)
+			if len(s.tagGroupIDs) == 0 {
+				return fmt.Errorf("tag group %s is not found", s.TagGroupName)
+			}
+		}
+		if s.TagName != "" {
+			s.tagIDs = tagDictionary.FindTagIDs(s.TagName)
+			if len(s.tagIDs) == 0 {
+				return fmt.Errorf("tag %s is not found", s.TagName)
+			}
+		}
+	}
+	return nil
+}
+
+// HasTagNames returns true if any of the selectors gives the tag group or the tag by name.
+func HasTagNames(selectors []*TagSelector) bool {
+	return slices.ContainsFunc(selectors, func(s *TagSelector) bool {
+		return s.HasNames()
+	})
+}
+
+// GetAllTagIds returns the rule tags and the manual tags of the asset.
+func GetAllTagIds(asset Data) []RuleTagIds {
+	tags := make([]RuleTagIds, 0, len(asset.RuleTagIds)+len(asset.ManualTagIds))
+	tags = append(tags, asset.RuleTagIds...)
+	tags = append(tags, asset.ManualTagIds...)
+	return tags
+}
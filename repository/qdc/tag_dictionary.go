@@ -0,0 +1,179 @@
+package qdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type GetTagGroupsResponse struct {
+	Data   []TagGroup `json:"data"`
+	LastID string     `json:"last_id"`
+}
+
+type TagGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Tags []Tag  `json:"tags"`
+}
+
+type Tag struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ChildTags []Tag  `json:"child_tags"`
+}
+
+type tagEntry struct {
+	Name       string
+	TagGroupID string
+	ParentID   string
+}
+
+// TagDictionary resolves the IDs of tag groups, parent tags and child tags to their names.
+type TagDictionary struct {
+	tagGroups map[string]string
+	tags      map[string]tagEntry
+}
+
+func NewTagDictionary(tagGroups []TagGroup) TagDictionary {
+	dict := TagDictionary{
+		tagGroups: make(map[string]string),
+		tags:      make(map[string]tagEntry),
+	}
+	for _, tagGroup := range tagGroups {
+		dict.tagGroups[tagGroup.ID] = tagGroup.Name
+		for _, parentTag := range tagGroup.Tags {
+			dict.tags[parentTag.ID] = tagEntry{Name: parentTag.Name, TagGroupID: tagGroup.ID}
+			for _, childTag := range parentTag.ChildTags {
+				dict.tags[childTag.ID] = tagEntry{Name: childTag.Name, TagGroupID: tagGroup.ID, ParentID: parentTag.ID}
+			}
+		}
+	}
+	return dict
+}
+
+// TagGroupName returns the name of the tag group. The ID is returned if it's not in the dictionary.
+func (d TagDictionary) TagGroupName(tagGroupID string) string {
+	if name, ok := d.tagGroups[tagGroupID]; ok {
+		return name
+	}
+	return tagGroupID
+}
+
+// TagName returns the name of the parent tag or the child tag. The ID is returned if it's not in the dictionary.
+func (d TagDictionary) TagName(tagID string) string {
+	if entry, ok := d.tags[tagID]; ok {
+		return entry.Name
+	}
+	return tagID
+}
+
+// FullName returns the path of the tag such as `group/parent/child`. Empty IDs are omitted.
+func (d TagDictionary) FullName(tag RuleTagIds) string {
+	var names []string
+	if tag.TagGroupId != "" {
+		names = append(names, d.TagGroupName(tag.TagGroupId))
+	}
+	if tag.ParentTagId != "" {
+		names = append(names, d.TagName(tag.ParentTagId))
+	}
+	if tag.ChildTagId != "" {
+		names = append(names, d.TagName(tag.ChildTagId))
+	}
+	return strings.Join(names, "/")
+}
+
+// TagNames returns the full names of the tags of the asset such as `group/parent/child` without duplication.
+// The tags without a parent tag nor a child tag are omitted.
+func (d TagDictionary) TagNames(asset Data) []string {
+	var tagNames []string
+	seen := make(map[string]bool)
+	for _, tag := range GetAllTagIds(asset) {
+		if tag.ParentTagId == "" && tag.ChildTagId == "" {
+			continue
+		}
+		tagName := d.FullName(tag)
+		if seen[tagName] {
+			continue
+		}
+		seen[tagName] = true
+		tagNames = append(tagNames, tagName)
+	}
+	return tagNames
+}
+
+// FindTagGroupIDs returns the IDs of the tag groups that have the name.
+func (d TagDictionary) FindTagGroupIDs(name string) []string {
+	var ids []string
+	for id, tagGroupName := range d.tagGroups {
+		if tagGroupName == name {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// FindTagIDs returns the IDs of the parent tags and the child tags that have the name.
+func (d TagDictionary) FindTagIDs(name string) []string {
+	var ids []string
+	for id, entry := range d.tags {
+		if entry.Name == name {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (q *QDCExternalAPI) GetTagGroups(lastID string) (GetTagGroupsResponse, error) {
+	url := fmt.Sprintf("%s/v2/tag-groups", q.BaseURL)
+	data := map[string]string{
+		"last_id": lastID,
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return GetTagGroupsResponse{}, err
+	}
+
+	payload := strings.NewReader(string(b))
+	resp, err := q.postRequest(url, payload)
+	if err != nil {
+		return GetTagGroupsResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return GetTagGroupsResponse{}, err
+	}
+	var getTagGroupsResponse GetTagGroupsResponse
+	err = json.Unmarshal(body, &getTagGroupsResponse)
+	if err != nil {
+		return GetTagGroupsResponse{}, err
+	}
+	return getTagGroupsResponse, nil
+}
+
+// GetTagDictionary fetches all of the tag groups and their tags. The dictionary is cached for the run.
+func (q *QDCExternalAPI) GetTagDictionary() (TagDictionary, error) {
+	if q.tagDictionary != nil {
+		return *q.tagDictionary, nil
+	}
+	var tagGroups []TagGroup
+	var lastID string
+	for {
+		resp, err := q.GetTagGroups(lastID)
+		if err != nil {
+			return TagDictionary{}, fmt.Errorf("Failed to GetTagGroups. %s lastID: %s", err.Error(), lastID)
+		}
+		tagGroups = append(tagGroups, resp.Data...)
+		if resp.LastID == "" {
+			break
+		}
+		lastID = resp.LastID
+	}
+	dict := NewTagDictionary(tagGroups)
+	q.tagDictionary = &dict
+	q.Logger.Debug("The number of tag groups is %v", len(tagGroups))
+	return dict, nil
+}
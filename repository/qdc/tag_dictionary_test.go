@@ -0,0 +1,51 @@
+package qdc_test
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTagDictionary(t *testing.T) {
+	dict := qdc.NewTagDictionary([]qdc.TagGroup{
+		{ID: "tggr-1", Name: "Sensitivity", Tags: []qdc.Tag{
+			{ID: "tag-1", Name: "PII", ChildTags: []qdc.Tag{{ID: "tag-2", Name: "Email"}}},
+		}},
+		{ID: "tggr-2", Name: "Contact", Tags: []qdc.Tag{
+			{ID: "tag-3", Name: "Email"},
+		}},
+	})
+	testCases := []struct {
+		Input  qdc.RuleTagIds
+		Expect string
+	}{
+		{Input: qdc.RuleTagIds{TagGroupId: "tggr-1", ParentTagId: "tag-1"}, Expect: "Sensitivity/PII"},
+		{Input: qdc.RuleTagIds{TagGroupId: "tggr-1", ParentTagId: "tag-1", ChildTagId: "tag-2"}, Expect: "Sensitivity/PII/Email"},
+		{Input: qdc.RuleTagIds{TagGroupId: "tggr-9", ParentTagId: "tag-9"}, Expect: "tggr-9/tag-9"},
+	}
+	for _, tt := range testCases {
+		if res := dict.FullName(tt.Input); res != tt.Expect {
+			t.Errorf("want %s but got %s", tt.Expect, res)
+		}
+	}
+	ids := dict.FindTagIDs("Email")
+	sort.Strings(ids)
+	if diff := cmp.Diff([]string{"tag-2", "tag-3"}, ids); diff != "" {
+		t.Errorf("FindTagIDs mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"tggr-2"}, dict.FindTagGroupIDs("Contact")); diff != "" {
+		t.Errorf("FindTagGroupIDs mismatch (-want +got):\n%s", diff)
+	}
+	asset := qdc.Data{
+		RuleTagIds:   []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-1", ChildTagId: "tag-2"}, {TagGroupId: "tggr-2"}},
+		ManualTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-1", ParentTagId: "tag-1", ChildTagId: "tag-2"}, {TagGroupId: "tggr-2", ParentTagId: "tag-3"}},
+	}
+	if diff := cmp.Diff([]string{"Sensitivity/PII/Email", "Contact/Email"}, dict.TagNames(asset)); diff != "" {
+		t.Errorf("TagNames mismatch (-want +got):\n%s", diff)
+	}
+	if res := dict.TagGroupName("tggr-2"); res != "Contact" {
+		t.Errorf("want Contact but got %s", res)
+	}
+}
@@ -0,0 +1,80 @@
+package qdc_test
+
+import (
+	"quollio-reverse-agent/repository/qdc"
+	"testing"
+)
+
+func TestTagSelectorMatches(t *testing.T) {
+	asset := qdc.Data{
+		RuleTagIds: []qdc.RuleTagIds{
+			{
+				TagGroupId:  "tggr-1234",
+				ParentTagId: "tag-1234",
+			},
+		},
+		ManualTagIds: []qdc.RuleTagIds{
+			{
+				TagGroupId:  "tggr-5678",
+				ParentTagId: "tag-5678",
+				ChildTagId:  "tag-9012",
+			},
+		},
+	}
+	testCases := []struct {
+		selector qdc.TagSelector
+		want     bool
+	}{
+		{selector: qdc.TagSelector{TagGroupID: "tggr-1234"}, want: true},
+		{selector: qdc.TagSelector{TagID: "tag-9012"}, want: true},
+		{selector: qdc.TagSelector{TagGroupID: "tggr-5678", TagID: "tag-5678"}, want: true},
+		{selector: qdc.TagSelector{TagGroupID: "tggr-1234", TagID: "tag-9012"}, want: false},
+		{selector: qdc.TagSelector{TagID: "tag-0000"}, want: false},
+		{selector: qdc.TagSelector{}, want: false},
+	}
+	for _, tt := range testCases {
+		res := tt.selector.Matches(asset)
+		if res != tt.want {
+			t.Errorf("want %v but got %v. selector: %v", tt.want, res, tt.selector)
+		}
+	}
+}
+
+func TestTagSelectorMatchesByName(t *testing.T) {
+	dict := qdc.NewTagDictionary([]qdc.TagGroup{
+		{ID: "tggr-1234", Name: "Sensitivity", Tags: []qdc.Tag{{ID: "tag-1234", Name: "PII"}}},
+		{ID: "tggr-5678", Name: "Domain", Tags: []qdc.Tag{
+			{ID: "tag-5678", Name: "Sales", ChildTags: []qdc.Tag{{ID: "tag-9012", Name: "Retail"}}},
+		}},
+	})
+	asset := qdc.Data{
+		RuleTagIds:   []qdc.RuleTagIds{{TagGroupId: "tggr-1234", ParentTagId: "tag-1234"}},
+		ManualTagIds: []qdc.RuleTagIds{{TagGroupId: "tggr-5678", ParentTagId: "tag-5678", ChildTagId: "tag-9012"}},
+	}
+	testCases := []struct {
+		selector qdc.TagSelector
+		want     bool
+	}{
+		{selector: qdc.TagSelector{TagGroupName: "Sensitivity"}, want: true},
+		{selector: qdc.TagSelector{TagName: "Retail"}, want: true},
+		{selector: qdc.TagSelector{TagGroupName: "Domain", TagName: "Sales"}, want: true},
+		{selector: qdc.TagSelector{TagGroupName: "Sensitivity", TagName: "Retail"}, want: false},
+		{selector: qdc.TagSelector{TagGroupID: "tggr-5678", TagName: "PII"}, want: false},
+	}
+	for _, tt := range testCases {
+		selector := tt.selector
+		if err := qdc.ResolveTagNames(dict, []*qdc.TagSelector{&selector}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if res := selector.Matches(asset); res != tt.want {
+			t.Errorf("want %v but got %v. selector: %v", tt.want, res, tt.selector)
+		}
+	}
+	unresolved := qdc.TagSelector{TagName: "PII"}
+	if unresolved.Matches(asset) {
+		t.Errorf("the selector whose names are not resolved must match nothing")
+	}
+	if err := qdc.ResolveTagNames(dict, []*qdc.TagSelector{{TagName: "Unknown"}}); err == nil {
+		t.Errorf("want error for the unknown tag name")
+	}
+}